@@ -0,0 +1,136 @@
+package transformation
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type (
+	// BatchTransformer is implemented by transformers which transform many values at once more
+	// cheaply than one by one, e.g. lookups. Each calls TransformBatch with the elements instead
+	// of calling Transform for every element.
+	BatchTransformer interface {
+		Transformer
+		// TransformBatch returns the transformed values in the order of the given ones. The
+		// failures of single values are reported by returning Errors keyed by their position,
+		// any other error fails every value.
+		TransformBatch(from []interface{}) ([]interface{}, error)
+	}
+
+	// pipelineSegment is a part of the pipeline of Each which is either a single batch
+	// transformer or a run of transformers applied element by element.
+	pipelineSegment struct {
+		first        int
+		transformers []Transformer
+		batch        BatchTransformer
+	}
+)
+
+// BatchSize returns a copy of the transformer which passes at most size elements at once to
+// the batch transformers of its pipeline. By default, all the elements are passed at once.
+func (t *eachTransformer) BatchSize(size int) *eachTransformer {
+	c := *t
+	c.batchSize = size
+
+	return &c
+}
+
+// segments splits the pipeline into segments. The first segment is never a batch transformer,
+// so every element goes through the execution of its own before the first batch.
+func (t eachTransformer) segments() []pipelineSegment {
+	segments := []pipelineSegment{{}}
+	for i, transformer := range t.transformers {
+		if bt, ok := transformer.(BatchTransformer); ok {
+			segments = append(segments, pipelineSegment{first: i, batch: bt})
+			continue
+		}
+
+		last := &segments[len(segments)-1]
+		if last.batch != nil {
+			segments = append(segments, pipelineSegment{first: i})
+			last = &segments[len(segments)-1]
+		}
+		last.transformers = append(last.transformers, transformer)
+	}
+
+	return segments
+}
+
+// transformBatch passes the values which did not fail yet to the batch transformer in chunks,
+// replacing them with the results and recording the failures by index.
+func (t eachTransformer) transformBatch(e *execution, index int, bt BatchTransformer, values []interface{}, failures []error) error {
+	var pending []int
+	for i := range values {
+		if failures[i] == nil {
+			pending = append(pending, i)
+		}
+	}
+
+	size := t.batchSize
+	if size <= 0 {
+		size = len(pending)
+	}
+
+	for start := 0; start < len(pending); start += size {
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + size
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		chunk := pending[start:end]
+		from := make([]interface{}, len(chunk))
+		for i, j := range chunk {
+			from[i] = values[j]
+		}
+
+		to, err := e.invokeBatch(index, bt, from)
+		errs, partial := err.(Errors)
+		if err != nil && !partial {
+			for _, j := range chunk {
+				failures[j] = err
+			}
+			continue
+		}
+
+		for k, v := range errs {
+			i, convErr := strconv.Atoi(k)
+			if convErr != nil || i < 0 || i >= len(chunk) {
+				return fmt.Errorf("invalid batch position %q: %v", k, v)
+			}
+			failures[chunk[i]] = v
+		}
+
+		for i, j := range chunk {
+			switch {
+			case failures[j] != nil:
+			case len(to) != len(from):
+				failures[j] = fmt.Errorf("expected %d values but got %d", len(from), len(to))
+			default:
+				values[j] = to[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// invokeBatch calls the batch transformer through the interceptors of the execution, which
+// receive the whole batch as their value.
+func (e *execution) invokeBatch(index int, bt BatchTransformer, from []interface{}) ([]interface{}, error) {
+	to, err := e.intercept(index, bt, from, func(se *execution, in interface{}) (interface{}, error) {
+		values, ok := in.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("batch expected to be a []interface{} but got %T", in)
+		}
+
+		return bt.TransformBatch(values)
+	})
+
+	values, _ := to.([]interface{})
+
+	return values, err
+}
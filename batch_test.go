@@ -0,0 +1,112 @@
+package transformation_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type lookupTransformer struct {
+	names   map[string]string
+	batches [][]interface{}
+}
+
+func (t *lookupTransformer) Transform(from interface{}) (interface{}, error) {
+	to, err := t.TransformBatch([]interface{}{from})
+	if err != nil {
+		return nil, err
+	}
+
+	return to[0], nil
+}
+
+func (t *lookupTransformer) TransformBatch(from []interface{}) ([]interface{}, error) {
+	t.batches = append(t.batches, from)
+
+	to := make([]interface{}, len(from))
+	errs := transformation.Errors{}
+	for i, id := range from {
+		name, ok := t.names[id.(string)]
+		if !ok {
+			errs[strconv.Itoa(i)] = fmt.Errorf("%s not found", id)
+			continue
+		}
+		to[i] = name
+	}
+
+	if len(errs) > 0 {
+		return to, errs
+	}
+
+	return to, nil
+}
+
+func TestEachBatch(t *testing.T) {
+	lookup := &lookupTransformer{names: map[string]string{"1": "john", "2": "jane", "3": "joe"}}
+
+	var to []string
+	err := transformation.Transform([]string{" 1", "2 ", " 3 "}, &to, transformation.Each(transformation.Trim, lookup, transformation.UpperCase).BatchSize(2))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"JOHN", "JANE", "JOE"}, to)
+		assert.Equal(t, [][]interface{}{{"1", "2"}, {"3"}}, lookup.batches)
+	}
+
+	lookup.batches = nil
+	err = transformation.Transform([]string{"1", "4", "2", "5"}, &to, transformation.ParallelEach(2, lookup))
+	assert.Equal(t, transformation.Errors{
+		"1": errors.New("4 not found"),
+		"3": errors.New("5 not found"),
+	}, err)
+	assert.Equal(t, [][]interface{}{{"1", "4", "2", "5"}}, lookup.batches)
+
+	var m map[string]string
+	err = transformation.Transform(map[string]string{"a": "1", "b": "9"}, &m, transformation.Each(lookup))
+	assert.Equal(t, transformation.Errors{"b": errors.New("9 not found")}, err)
+}
+
+func TestEachBatchSkipsFailedElements(t *testing.T) {
+	lookup := &lookupTransformer{names: map[string]string{"1": "john", "2": "jane"}}
+	notEmpty := transformation.By(func(from interface{}) (interface{}, error) {
+		if strings.TrimSpace(from.(string)) == "" {
+			return nil, errors.New("empty id")
+		}
+
+		return from, nil
+	})
+
+	_, err := transformation.ApplyTransformers([]string{"1", " ", "2"}, transformation.Each(notEmpty, lookup))
+	assert.Equal(t, transformation.Errors{"1": errors.New("empty id")}, err)
+	assert.Equal(t, [][]interface{}{{"1", "2"}}, lookup.batches)
+}
+
+type failingBatch struct {
+	to  []interface{}
+	err error
+}
+
+func (t failingBatch) Transform(from interface{}) (interface{}, error) {
+	return from, nil
+}
+
+func (t failingBatch) TransformBatch(from []interface{}) ([]interface{}, error) {
+	return t.to, t.err
+}
+
+func TestEachBatchErrors(t *testing.T) {
+	_, err := transformation.ApplyTransformers([]int{1, 2, 3}, transformation.Each(failingBatch{err: errors.New("service unavailable")}).BatchSize(2))
+	assert.Equal(t, transformation.Errors{
+		"0": errors.New("service unavailable"),
+		"1": errors.New("service unavailable"),
+		"2": errors.New("service unavailable"),
+	}, err)
+
+	_, err = transformation.ApplyTransformers([]int{1, 2}, transformation.Each(failingBatch{to: []interface{}{1}}))
+	assert.Equal(t, transformation.Errors{
+		"0": errors.New("expected 2 values but got 1"),
+		"1": errors.New("expected 2 values but got 1"),
+	}, err)
+}
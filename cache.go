@@ -0,0 +1,196 @@
+package transformation
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type (
+	// CacheOptions configures the cache of a transformer returned by Cached.
+	CacheOptions struct {
+		// Size is the maximum number of cached results. The least recently used result is
+		// evicted to make room for a new one. Zero means no limit.
+		Size int
+		// TTL is the duration a result is cached for. Zero means results do not expire.
+		TTL time.Duration
+	}
+
+	// CacheStats holds the statistics of a transformer returned by Cached.
+	CacheStats struct {
+		Hits      uint64 `json:"hits"`
+		Misses    uint64 `json:"misses"`
+		Evictions uint64 `json:"evictions"`
+		Size      int    `json:"size"`
+	}
+
+	// CachedTransformer memoizes the results of a transformer. It is safe for concurrent use.
+	CachedTransformer struct {
+		transformer Transformer
+		opts        CacheOptions
+		now         func() time.Time
+
+		mu      sync.Mutex
+		entries map[interface{}]*list.Element
+		lru     *list.List
+		stats   CacheStats
+	}
+
+	cacheEntry struct {
+		key     interface{}
+		value   interface{}
+		expires time.Time
+	}
+)
+
+// Cached returns a transformer which memoizes the results of the given one, keyed on the input
+// value. Inputs which are not comparable, e.g. slices and maps, are not cached. Errors are not
+// cached either.
+func Cached(transformer Transformer, opts CacheOptions) *CachedTransformer {
+	return &CachedTransformer{
+		transformer: transformer,
+		opts:        opts,
+		now:         time.Now,
+		entries:     make(map[interface{}]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+func (t *CachedTransformer) Transform(from interface{}) (interface{}, error) {
+	return t.transformIn(newExecution(context.Background(), nil), from)
+}
+
+func (t *CachedTransformer) transformIn(e *execution, from interface{}) (interface{}, error) {
+	key, isNil := indirect(from)
+	if isNil || !isHashable(key) {
+		return t.call(e, from)
+	}
+
+	if v, ok := t.get(key); ok {
+		return v, nil
+	}
+
+	v, err := t.call(e, from)
+	if err != nil {
+		return nil, err
+	}
+
+	t.set(key, v)
+
+	return v, nil
+}
+
+// Stats returns the statistics of the cache.
+func (t *CachedTransformer) Stats() CacheStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	stats.Size = t.lru.Len()
+
+	return stats
+}
+
+// Purge removes every cached result.
+func (t *CachedTransformer) Purge() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries = make(map[interface{}]*list.Element)
+	t.lru.Init()
+}
+
+func (t *CachedTransformer) call(e *execution, from interface{}) (interface{}, error) {
+	if et, ok := t.transformer.(executionTransformer); ok {
+		return et.transformIn(e, from)
+	}
+
+	return t.transformer.Transform(from)
+}
+
+func (t *CachedTransformer) get(key interface{}) (interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	el, ok := t.entries[key]
+	if !ok {
+		t.stats.Misses++
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !t.now().Before(entry.expires) {
+		t.lru.Remove(el)
+		delete(t.entries, key)
+		t.stats.Misses++
+		return nil, false
+	}
+
+	t.lru.MoveToFront(el)
+	t.stats.Hits++
+
+	return entry.value, true
+}
+
+func (t *CachedTransformer) set(key, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var expires time.Time
+	if t.opts.TTL > 0 {
+		expires = t.now().Add(t.opts.TTL)
+	}
+
+	if el, ok := t.entries[key]; ok {
+		el.Value = &cacheEntry{key: key, value: value, expires: expires}
+		t.lru.MoveToFront(el)
+		return
+	}
+
+	t.entries[key] = t.lru.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+
+	if t.opts.Size > 0 && t.lru.Len() > t.opts.Size {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.entries, oldest.Value.(*cacheEntry).key)
+		t.stats.Evictions++
+	}
+}
+
+func (t *CachedTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if tc, ok := t.transformer.(TypeChecker); ok {
+		return tc.CheckType(in)
+	}
+
+	return nil, nil
+}
+
+func (t *CachedTransformer) Name() string {
+	return "Cached"
+}
+
+func (t *CachedTransformer) Description() string {
+	return "Memoizes the results of the transformer."
+}
+
+func (t *CachedTransformer) Params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if t.opts.Size > 0 {
+		params["size"] = t.opts.Size
+	}
+	if t.opts.TTL > 0 {
+		params["ttl"] = t.opts.TTL.String()
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	return params
+}
+
+func (t *CachedTransformer) pipelines() [][]Transformer {
+	return [][]Transformer{{t.transformer}}
+}
@@ -0,0 +1,120 @@
+package transformation_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCached(t *testing.T) {
+	var calls int32
+	upper := transformation.By(func(from interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+
+		return strings.ToUpper(from.(string)), nil
+	})
+
+	cached := transformation.Cached(upper, transformation.CacheOptions{Size: 2})
+
+	for _, v := range []string{"ro", "ro", "de", "ro", "fr", "de"} {
+		to, err := cached.Transform(v)
+		if assert.NoError(t, err) {
+			assert.Equal(t, strings.ToUpper(v), to)
+		}
+	}
+
+	// de is evicted by fr as ro was used more recently, then ro is evicted by de
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	assert.Equal(t, transformation.CacheStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2}, cached.Stats())
+
+	s := "de"
+	to, err := cached.Transform(&s)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "DE", to)
+		assert.Equal(t, uint64(3), cached.Stats().Hits)
+	}
+
+	cached.Purge()
+	assert.Equal(t, 0, cached.Stats().Size)
+}
+
+func TestCachedTTL(t *testing.T) {
+	var calls int32
+	upper := transformation.By(func(from interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+
+		return strings.ToUpper(from.(string)), nil
+	})
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cached := transformation.Cached(upper, transformation.CacheOptions{TTL: time.Minute})
+	transformation.SetCacheClock(cached, func() time.Time {
+		return now
+	})
+
+	_, _ = cached.Transform("ro")
+	now = now.Add(59 * time.Second)
+	_, _ = cached.Transform("ro")
+	now = now.Add(time.Second)
+	_, _ = cached.Transform("ro")
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, transformation.CacheStats{Hits: 1, Misses: 2, Size: 1}, cached.Stats())
+}
+
+func TestCachedSkipsErrorsAndUncomparableValues(t *testing.T) {
+	var calls int32
+	fail := transformation.By(func(from interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		if s, ok := from.(string); ok && s == "" {
+			return nil, errors.New("empty")
+		}
+
+		return from, nil
+	})
+
+	cached := transformation.Cached(fail, transformation.CacheOptions{})
+	for i := 0; i < 2; i++ {
+		_, err := cached.Transform("")
+		assert.EqualError(t, err, "empty")
+
+		_, err = cached.Transform([]string{"a"})
+		assert.NoError(t, err)
+
+		_, err = cached.Transform(struct{ V interface{} }{V: []string{"a"}})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+	assert.Equal(t, transformation.CacheStats{Misses: 2}, cached.Stats())
+}
+
+func TestCachedConcurrent(t *testing.T) {
+	cached := transformation.Cached(transformation.UpperCase, transformation.CacheOptions{Size: 10})
+	c := Customer{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c := c
+				c.FirstName = string(rune('a' + (i+j)%20))
+				if err := transformation.TransformStruct(&c, transformation.Field(&c.FirstName, &c.FirstName, cached)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := cached.Stats()
+	assert.Equal(t, uint64(800), stats.Hits+stats.Misses)
+	assert.Equal(t, 10, stats.Size)
+}
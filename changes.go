@@ -0,0 +1,85 @@
+package transformation
+
+import (
+	"reflect"
+)
+
+type (
+	// Change describes a value modified by TransformStructWithOptions.
+	Change struct {
+		// Path is the name of the struct field which was changed. For destinations outside
+		// the struct, it is the name of the field the rule reads from.
+		Path         string      `json:"path"`
+		Old          interface{} `json:"old"`
+		New          interface{} `json:"new"`
+		Transformers []string    `json:"transformers,omitempty"`
+		// Pointer is the JSON pointer of the changed field, empty if it is not serialised.
+		Pointer string `json:"pointer,omitempty"`
+		// OmitEmpty tells whether the field is left out of the JSON representation when empty.
+		OmitEmpty bool `json:"omitEmpty,omitempty"`
+	}
+
+	// ChangeSet lists the changes made by TransformStructWithOptions in the order they were made.
+	ChangeSet []Change
+
+	changeRecorder struct {
+		changes ChangeSet
+	}
+)
+
+// RecordChanges makes TransformStructWithOptions store in cs every value it changed.
+// Values which were written but are equal to the previous ones are skipped.
+func RecordChanges(cs *ChangeSet) Option {
+	return func(o *options) {
+		o.changes = cs
+	}
+}
+
+// snapshot returns copies of the values the rule targets.
+func (r *changeRecorder) snapshot(rule Rule) []interface{} {
+	targets := rule.targets()
+	values := make([]interface{}, len(targets))
+	for i, target := range targets {
+		values[i] = snapshotValue(target)
+	}
+
+	return values
+}
+
+// diff compares the values the rule targets with the given snapshot and returns the changes
+// of the ones which were changed.
+func (r *changeRecorder) diff(structPtr reflect.Value, name string, rule Rule, before []interface{}) []Change {
+	var changes []Change
+	for i, target := range rule.targets() {
+		after := snapshotValue(target)
+		if reflect.DeepEqual(before[i], after) {
+			continue
+		}
+
+		change := Change{
+			Path:         name,
+			Old:          before[i],
+			New:          after,
+			Transformers: transformerNames(rule.pipeline()),
+		}
+		if sf := findStructField(structPtr.Elem(), reflect.ValueOf(target)); sf != nil {
+			change.Path = sf.Name
+			change.Pointer, change.OmitEmpty, _ = jsonPointer(structPtr.Elem(), reflect.ValueOf(target))
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func snapshotValue(ptr interface{}) interface{} {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+
+	value, _ := indirect(deepCopy(v.Elem()).Interface())
+
+	return value
+}
@@ -0,0 +1,57 @@
+package transformation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+func TestRecordChanges(t *testing.T) {
+	lastName := " Doe "
+	from := Person{
+		FirstName: "John",
+		LastName:  &lastName,
+		Addresses: []string{" Street1 "},
+	}
+
+	var cs transformation.ChangeSet
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{transformation.RecordChanges(&cs)},
+		transformation.Field(&from.FirstName, &from.FirstName, transformation.Trim),
+		transformation.Field(&from.LastName, &from.LastName, transformation.Trim, transformation.UpperCase),
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.Each(transformation.Trim)),
+	)
+	if assert.NoError(t, err) && assert.Len(t, cs, 2) {
+		assert.Equal(t, "LastName", cs[0].Path)
+		assert.Equal(t, " Doe ", cs[0].Old)
+		assert.Equal(t, "DOE", cs[0].New)
+		assert.Equal(t, []string{"Trim", "UpperCase"}, cs[0].Transformers)
+		assert.Equal(t, "Addresses", cs[1].Path)
+		assert.Equal(t, []string{" Street1 "}, cs[1].Old)
+		assert.Equal(t, []string{"Street1"}, cs[1].New)
+
+		b, err := json.Marshal(cs[:1])
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `[{"path":"LastName","old":" Doe ","new":"DOE","transformers":["Trim","UpperCase"],"pointer":"/LastName"}]`, string(b))
+		}
+	}
+}
+
+func TestRecordChangesAtomic(t *testing.T) {
+	r := Record{Name: " John ", Email: "john"}
+
+	var cs transformation.ChangeSet
+	err := transformation.TransformStructWithOptions(
+		&r,
+		[]transformation.Option{transformation.Atomic(), transformation.RecordChanges(&cs)},
+		transformation.Field(&r.Name, &r.Name, transformation.Trim),
+		transformation.Field(&r.Email, &r.Email, transformation.By(func(from interface{}) (interface{}, error) {
+			return nil, errors.New("invalid email")
+		})),
+	)
+	assert.Error(t, err)
+	assert.Empty(t, cs)
+}
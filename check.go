@@ -0,0 +1,284 @@
+package transformation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type (
+	// TypeChecker is implemented by transformers which declare the input types they accept and
+	// the output type they produce. A nil type stands for a type which is not known statically.
+	TypeChecker interface {
+		// CheckType returns the type the transformer produces for a value of the given type,
+		// or an error if it does not accept it. Pointer types are passed as they are, most
+		// transformers accept a pointer to the type they accept.
+		CheckType(in reflect.Type) (reflect.Type, error)
+	}
+
+	// compiledTransformer is a pipeline checked against the type of its input.
+	compiledTransformer struct {
+		transformers []Transformer
+		in           reflect.Type
+		out          reflect.Type
+	}
+)
+
+var (
+	stringType = reflect.TypeOf("")
+	int64Type  = reflect.TypeOf(int64(0))
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// Check walks the pipeline with the given input type and reports the first step which does
+// not accept the type produced by the steps before it. It returns the type produced by the
+// pipeline, or nil if it is not known statically, e.g. after a By step. Steps which do not
+// implement TypeChecker accept any type and produce a type which is not known.
+func Check(fromType reflect.Type, transformers ...Transformer) (reflect.Type, error) {
+	t := knownType(fromType)
+	if t != nil {
+		t = knownType(indirectType(t))
+	}
+
+	for i, transformer := range transformers {
+		tc, ok := transformer.(TypeChecker)
+		if !ok {
+			t = nil
+			continue
+		}
+
+		out, err := tc.CheckType(t)
+		if err != nil {
+			return nil, fmt.Errorf("step %d (%s): %v", i+1, transformerName(transformer), err)
+		}
+		t = knownType(out)
+	}
+
+	return t, nil
+}
+
+// Compile checks the pipeline with the given input type and returns it as a single transformer.
+func Compile(fromType reflect.Type, transformers ...Transformer) (Transformer, error) {
+	out, err := Check(fromType, transformers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compiledTransformer{
+		transformers: transformers,
+		in:           fromType,
+		out:          out,
+	}, nil
+}
+
+func (t compiledTransformer) Transform(from interface{}) (interface{}, error) {
+	return t.transformIn(newExecution(context.Background(), nil), from)
+}
+
+func (t compiledTransformer) transformIn(e *execution, from interface{}) (interface{}, error) {
+	return e.apply(from, t.transformers)
+}
+
+func (t compiledTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in != nil && t.in != nil && in != t.in && indirectType(in) != indirectType(t.in) {
+		return nil, fmt.Errorf("expected %s but got %s", t.in, in)
+	}
+
+	return t.out, nil
+}
+
+func (t compiledTransformer) Name() string {
+	return "Compiled"
+}
+
+func (t compiledTransformer) Description() string {
+	return "Applies a type checked pipeline."
+}
+
+func (t compiledTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t compiledTransformer) pipelines() [][]Transformer {
+	return [][]Transformer{t.transformers}
+}
+
+func (t TrimTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	return stringType, nil
+}
+
+func (t UpperCaseTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	return stringType, nil
+}
+
+func (t DownCaseTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	return stringType, nil
+}
+
+func (t ToStringTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	return stringType, nil
+}
+
+func (t MoneyTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in != nil {
+		switch indirectType(in).Kind() {
+		case reflect.Float32, reflect.Float64:
+		default:
+			return nil, fmt.Errorf("expected float32 or float64 but got %s", in)
+		}
+	}
+
+	return int64Type, nil
+}
+
+func (t ReverseTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in != nil && indirectType(in) != stringType {
+		return nil, fmt.Errorf("expected string but got %s", in)
+	}
+
+	return stringType, nil
+}
+
+func (t ParseTimeTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	return timeType, nil
+}
+
+func (t eachTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		_, err := Check(nil, t.transformers...)
+
+		return nil, err
+	}
+
+	// the type checked first is indirected, so the iterator may be implemented by its pointer
+	if in.Implements(iteratorType) || reflect.PtrTo(in).Implements(iteratorType) {
+		_, err := Check(nil, t.transformers...)
+		if err != nil {
+			return nil, err
+		}
+
+		return sequenceType, nil
+	}
+
+	switch in.Kind() {
+	case reflect.Chan:
+		if in.ChanDir()&reflect.RecvDir == 0 {
+			return nil, fmt.Errorf("expected a channel which can be received from but got %s", in)
+		}
+
+		if _, err := Check(in.Elem(), t.transformers...); err != nil {
+			return nil, err
+		}
+
+		return sequenceType, nil
+	case reflect.Slice, reflect.Array:
+		out, err := Check(in.Elem(), t.transformers...)
+		if err != nil || out == nil {
+			return nil, err
+		}
+
+		return reflect.SliceOf(out), nil
+	case reflect.Map:
+		out, err := Check(in.Elem(), t.transformers...)
+		if err != nil || out == nil {
+			return nil, err
+		}
+
+		return reflect.MapOf(in.Key(), out), nil
+	default:
+		return nil, fmt.Errorf("expected a slice, array, map, channel or Iterator but got %s", in)
+	}
+}
+
+func (t tryTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	var out reflect.Type
+	errs := Errors{}
+	passed := 0
+	for i, branch := range t.branches {
+		bt, err := Check(in, branch...)
+		if err != nil {
+			errs[strconv.Itoa(i)] = err
+			continue
+		}
+
+		if passed == 0 {
+			out = bt
+		} else if bt != out {
+			out = nil
+		}
+		passed++
+	}
+
+	if passed == 0 && len(errs) > 0 {
+		return nil, errs
+	}
+
+	return out, nil
+}
+
+func (t defaultTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	vt := reflect.TypeOf(t.value)
+	if in == nil || vt == nil {
+		return nil, nil
+	}
+
+	it := indirectType(in)
+	if vt.AssignableTo(it) || vt.AssignableTo(in) || (isNumberKind(vt.Kind()) && isNumberKind(it.Kind())) {
+		return in, nil
+	}
+
+	return nil, fmt.Errorf("default value of type %s is not assignable to %s", vt, in)
+}
+
+// CheckRules checks the pipeline of every rule built for the struct the given pointer points to
+// against the type of the field it reads. The failed checks are returned as Errors keyed by
+// field name.
+func CheckRules(from interface{}, rules ...Rule) error {
+	value := reflect.ValueOf(from)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("must be a non-nil pointer to a struct but got %T", from)
+	}
+
+	plan, err := planRules(value.Elem(), rules)
+	if err != nil {
+		return err
+	}
+
+	errs := Errors{}
+	for i, rule := range rules {
+		sources := rule.sources()
+		if len(rule.pipeline()) == 0 || len(sources) != 1 {
+			continue
+		}
+
+		if _, err := Check(reflect.TypeOf(sources[0]).Elem(), rule.pipeline()...); err != nil {
+			errs[plan.names[i]] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// knownType returns the given type, or nil if it is an interface type and the type of the
+// values is only known at runtime.
+func knownType(t reflect.Type) reflect.Type {
+	if t == nil || t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	return t
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
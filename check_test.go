@@ -0,0 +1,117 @@
+package transformation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	out, err := transformation.Check(reflect.TypeOf(0.0), transformation.Money100, transformation.Reverse)
+	assert.EqualError(t, err, "step 2 (Reverse): expected string but got int64")
+	assert.Nil(t, out)
+
+	out, err = transformation.Check(reflect.TypeOf(""), transformation.Money100)
+	assert.EqualError(t, err, "step 1 (Money): expected float32 or float64 but got string")
+
+	out, err = transformation.Check(reflect.TypeOf(new(float64)), transformation.Money100, transformation.ToString, transformation.Reverse)
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(""), out)
+	}
+
+	out, err = transformation.Check(reflect.TypeOf([]string{}), transformation.Each(transformation.ParseTime(time.RFC3339)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf([]time.Time{}), out)
+	}
+
+	out, err = transformation.Check(reflect.TypeOf(map[string]int{}), transformation.Each(transformation.Reverse))
+	assert.EqualError(t, err, "step 1 (Each): step 1 (Reverse): expected string but got int")
+
+	_, err = transformation.Check(reflect.TypeOf(""), transformation.Each(transformation.Trim))
+	assert.EqualError(t, err, "step 1 (Each): expected a slice, array, map, channel or Iterator but got string")
+
+	out, err = transformation.Check(reflect.TypeOf(""), transformation.Trim, transformation.Default("n/a"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(""), out)
+	}
+
+	_, err = transformation.Check(reflect.TypeOf(""), transformation.Default(42))
+	assert.EqualError(t, err, "step 1 (Default): default value of type int is not assignable to string")
+
+	_, err = transformation.Check(reflect.TypeOf(0), transformation.Default(int64(42)))
+	assert.NoError(t, err)
+}
+
+func TestCheckUnknownTypes(t *testing.T) {
+	identity := transformation.By(func(from interface{}) (interface{}, error) {
+		return from, nil
+	})
+
+	out, err := transformation.Check(reflect.TypeOf(0), identity, transformation.Reverse)
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(""), out)
+	}
+
+	out, err = transformation.Check(nil, transformation.Money100)
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(int64(0)), out)
+	}
+
+	out, err = transformation.Check(reflect.TypeOf([]interface{}{}), transformation.Each(transformation.Reverse))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf([]string{}), out)
+	}
+}
+
+func TestCheckTry(t *testing.T) {
+	out, err := transformation.Check(reflect.TypeOf(""), transformation.Try(transformation.Money100).Or(transformation.Reverse))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(""), out)
+	}
+
+	out, err = transformation.Check(reflect.TypeOf(0.0), transformation.Try(transformation.Money100).Or(transformation.ToString))
+	if assert.NoError(t, err) {
+		assert.Nil(t, out)
+	}
+
+	_, err = transformation.Check(reflect.TypeOf(0), transformation.Try(transformation.Money100).Or(transformation.Reverse))
+	assert.EqualError(t, err, "step 1 (Try): 0: step 1 (Money): expected float32 or float64 but got int; 1: step 1 (Reverse): expected string but got int.")
+}
+
+func TestCompile(t *testing.T) {
+	_, err := transformation.Compile(reflect.TypeOf(0.0), transformation.Money100, transformation.Reverse)
+	assert.EqualError(t, err, "step 2 (Reverse): expected string but got int64")
+
+	pipeline, err := transformation.Compile(reflect.TypeOf(""), transformation.Trim, transformation.UpperCase)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	to, err := pipeline.Transform(" foo ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "FOO", to)
+	}
+
+	_, err = transformation.Check(reflect.TypeOf(0), pipeline)
+	assert.EqualError(t, err, "step 1 (Compiled): expected string but got int")
+
+	out, err := transformation.Check(reflect.TypeOf([]string{}), transformation.Each(pipeline))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf([]string{}), out)
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	c := &Customer{}
+	err := transformation.CheckRules(c,
+		transformation.Field(&c.FirstName, &c.FirstName, transformation.Trim, transformation.Reverse),
+		transformation.Field(&c.LastName, &c.LastName, transformation.Money100),
+	)
+	assert.EqualError(t, err, "LastName: step 1 (Money): expected float32 or float64 but got string.")
+
+	assert.NoError(t, transformation.CheckRules(c,
+		transformation.Field(&c.FirstName, &c.FirstName, transformation.Trim),
+	))
+}
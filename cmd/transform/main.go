@@ -0,0 +1,206 @@
+// Command transform applies the pipelines of a rule file to every record of a JSON Lines or CSV
+// file, or of the standard input, and writes the transformed records to the standard output:
+//
+//	transform -rules rules.json -in csv -out jsonl -rejects rejects.jsonl users.csv
+//
+// The rule file is either a JSON rule set, as loaded by transformation.LoadRuleSet, or a JSON
+// object mapping the record fields to their pipelines:
+//
+//	{"email": "trim|down_case", "plan": "default(\"free\")"}
+//
+// By default the command stops at the first record which cannot be transformed. With -rejects,
+// such records are written to the given file along with their errors and the command carries on.
+// With -dry-run, the JSON Patch of every record which would change is written instead.
+//
+// The command exits with 0 if every record was transformed, 1 on error, 2 on invalid usage and
+// 3 if records were rejected.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	transformation "github.com/vcraescu/go-transformation"
+)
+
+const (
+	exitError    = 1
+	exitUsage    = 2
+	exitRejected = 3
+)
+
+type config struct {
+	rules   string
+	input   string
+	output  string
+	out     string
+	rejects string
+	columns string
+	dryRun  bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var cfg config
+	fs := flag.NewFlagSet("transform", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.rules, "rules", "", "rule file (required)")
+	fs.StringVar(&cfg.input, "in", "", "input format: jsonl or csv; guessed from the file extension by default")
+	fs.StringVar(&cfg.output, "out", "", "output format: jsonl or csv; the input format by default")
+	fs.StringVar(&cfg.out, "o", "", "output file; the standard output by default")
+	fs.StringVar(&cfg.rejects, "rejects", "", "file the failing records are written to instead of stopping at the first one")
+	fs.StringVar(&cfg.columns, "columns", "", "comma separated columns of the CSV output")
+	fs.BoolVar(&cfg.dryRun, "dry-run", false, "write the JSON Patch of every changed record instead of the records")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: transform -rules file [flags] [file]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		return exitUsage
+	}
+
+	if cfg.rules == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	stats, err := transform(cfg, fs.Arg(0), stdin, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, "transform:", err)
+		return exitError
+	}
+
+	if stats.Rejected > 0 {
+		fmt.Fprintf(stderr, "transform: %d of %d records rejected\n", stats.Rejected, stats.Read)
+		return exitRejected
+	}
+
+	return 0
+}
+
+// transform transforms the records of the input file, or of stdin, and writes them to the
+// output file, or to stdout.
+func transform(cfg config, input string, stdin io.Reader, stdout io.Writer) (stats transformation.StreamStats, err error) {
+	rs, err := loadRuleFile(cfg.rules)
+	if err != nil {
+		return stats, fmt.Errorf("%s: %v", cfg.rules, err)
+	}
+
+	opts := transformation.StreamOptions{
+		Input:   streamFormat(cfg.input, input),
+		Output:  streamFormat(cfg.output, cfg.out),
+		RuleSet: rs,
+		DryRun:  cfg.dryRun,
+	}
+	if cfg.columns != "" {
+		opts.Columns = strings.Split(cfg.columns, ",")
+	}
+
+	r := stdin
+	if input != "" && input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return stats, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	w := stdout
+	if cfg.out != "" && cfg.out != "-" {
+		var f *os.File
+		if f, err = os.Create(cfg.out); err != nil {
+			return stats, err
+		}
+		defer closeFile(f, &err)
+		w = f
+	}
+
+	if cfg.rejects != "" {
+		var f *os.File
+		if f, err = os.Create(cfg.rejects); err != nil {
+			return stats, err
+		}
+		defer closeFile(f, &err)
+		opts.Rejects = f
+	}
+
+	return transformation.TransformStream(r, w, opts)
+}
+
+// closeFile closes a file which was written to, setting err to the error of Close unless it
+// is already set.
+func closeFile(f *os.File, err *error) {
+	if cerr := f.Close(); cerr != nil && *err == nil {
+		*err = cerr
+	}
+}
+
+// streamFormat returns the format given by the flag, or the one of the file extension. An
+// empty format is returned if neither is known, leaving the default to TransformStream.
+func streamFormat(flagValue, file string) transformation.StreamFormat {
+	if flagValue != "" {
+		return transformation.StreamFormat(flagValue)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return transformation.CSV
+	case ".jsonl", ".ndjson":
+		return transformation.JSONLines
+	default:
+		return ""
+	}
+}
+
+// loadRuleFile reads a JSON rule set, or a JSON object mapping fields to pipelines.
+func loadRuleFile(file string) (*transformation.RuleSet, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	if _, ok := fields["fields"]; ok {
+		return transformation.LoadRuleSet(bytes.NewReader(b))
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rules := make([]transformation.FieldRules, len(names))
+	for i, name := range names {
+		var pipeline string
+		if err := json.Unmarshal(fields[name], &pipeline); err != nil {
+			return nil, fmt.Errorf("%s: pipeline must be a string", name)
+		}
+
+		rules[i] = transformation.FieldRules{Field: name, JSONName: name, Pipeline: pipeline}
+	}
+
+	return transformation.NewRuleSet(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), rules...)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transform")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	rejects := filepath.Join(dir, "rejects.jsonl")
+	out := filepath.Join(dir, "out.jsonl")
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		code    int
+		stdout  string
+		stderr  string
+		rejects string
+		out     string
+	}{
+		{
+			name:   "csv with a map of pipelines",
+			args:   []string{"-rules", "testdata/rules.json", "testdata/users.csv"},
+			stdout: "email,plan\na@x.com,free\nb@x.com,pro\n",
+		},
+		{
+			name:  "rule set from stdin to an output file",
+			args:  []string{"-rules", "testdata/ruleset.json", "-in", "csv", "-out", "jsonl", "-o", out},
+			stdin: "email\n A@X.com \n",
+			out:   `{"email":"a@x.com"}` + "\n",
+		},
+		{
+			name:   "fail fast",
+			args:   []string{"-rules", "testdata/rules.json", "testdata/users.jsonl"},
+			code:   exitError,
+			stdout: `{"email":"a@x.com","plan":"free","since":"2020-01-02T00:00:00Z"}` + "\n",
+			stderr: `transform: record 2: since: parsing time "yesterday"`,
+		},
+		{
+			name: "rejects",
+			args: []string{"-rules", "testdata/rules.json", "-rejects", rejects, "testdata/users.jsonl"},
+			code: exitRejected,
+			stdout: `{"email":"a@x.com","plan":"free","since":"2020-01-02T00:00:00Z"}` + "\n" +
+				`{"email":"c@x.com","plan":"pro"}` + "\n",
+			stderr:  "transform: 1 of 3 records rejected",
+			rejects: `{"record":2,"data":{"email":"b@x.com","since":"yesterday"},"errors":{"since":"parsing time`,
+		},
+		{
+			name:   "dry run",
+			args:   []string{"-rules", "testdata/ruleset.json", "-dry-run", "testdata/users.jsonl"},
+			stdout: `{"record":1,"patch":[{"op":"replace","path":"/email","value":"a@x.com"}]}` + "\n",
+		},
+		{
+			name:   "invalid rule file",
+			args:   []string{"-rules", "testdata/invalid.json", "testdata/users.csv"},
+			code:   exitError,
+			stderr: "transform: testdata/invalid.json: email: pipeline must be a string",
+		},
+		{
+			name:   "missing input",
+			args:   []string{"-rules", "testdata/rules.json", "testdata/missing.csv"},
+			code:   exitError,
+			stderr: "transform: open testdata/missing.csv: no such file or directory",
+		},
+		{
+			name:   "missing rules",
+			args:   []string{"testdata/users.csv"},
+			code:   exitUsage,
+			stderr: "usage: transform -rules file [flags] [file]",
+		},
+		{
+			name:   "too many files",
+			args:   []string{"-rules", "testdata/rules.json", "a.csv", "b.csv"},
+			code:   exitUsage,
+			stderr: "usage: transform -rules file [flags] [file]",
+		},
+		{
+			name:   "unknown flag",
+			args:   []string{"-verbose"},
+			code:   exitUsage,
+			stderr: "flag provided but not defined: -verbose",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, stdout.String())
+			if tt.stderr == "" {
+				assert.Empty(t, stderr.String())
+			} else {
+				assert.Contains(t, stderr.String(), tt.stderr)
+			}
+
+			if tt.rejects != "" {
+				b, err := ioutil.ReadFile(rejects)
+				if assert.NoError(t, err) {
+					assert.Contains(t, string(b), tt.rejects)
+				}
+			}
+
+			if tt.out != "" {
+				b, err := ioutil.ReadFile(out)
+				if assert.NoError(t, err) {
+					assert.Equal(t, tt.out, string(b))
+				}
+			}
+		})
+	}
+}
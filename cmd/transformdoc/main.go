@@ -0,0 +1,182 @@
+// Command transformdoc renders the documentation of transformation rule sets as Markdown or HTML,
+// or their JSON Schema fragments keyed by struct and field.
+//
+// Rule sets are loaded from JSON rule files and from the transform tags of the structs declared
+// in the given package directories:
+//
+//	transformdoc -format html -rules signup.json ./models
+//
+// Only the fields declared directly in a struct are documented; fields promoted from embedded
+// structs are documented with the embedded struct.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	transformation "github.com/vcraescu/go-transformation"
+)
+
+type ruleFiles []string
+
+func (f *ruleFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *ruleFiles) Set(s string) error {
+	*f = append(*f, s)
+
+	return nil
+}
+
+func main() {
+	var files ruleFiles
+	format := flag.String("format", "markdown", "output format: markdown, html or schema")
+	flag.Var(&files, "rules", "JSON rule file; may be repeated")
+	flag.Parse()
+
+	if err := run(os.Stdout, *format, files, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "transformdoc:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, format string, files []string, dirs []string) error {
+	var sets []*transformation.RuleSet
+	for _, file := range files {
+		rs, err := loadRuleFile(file)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		sets = append(sets, rs)
+	}
+
+	for _, dir := range dirs {
+		dirSets, err := loadPackage(dir)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, dirSets...)
+	}
+
+	switch format {
+	case "markdown", "md":
+		return transformation.WriteMarkdown(w, sets...)
+	case "html":
+		return transformation.WriteHTML(w, sets...)
+	case "schema":
+		schemas := make(map[string]map[string]map[string]interface{}, len(sets))
+		for _, rs := range sets {
+			schemas[rs.Name] = rs.JSONSchema()
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(schemas)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func loadRuleFile(name string) (*transformation.RuleSet, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return transformation.LoadRuleSet(f)
+}
+
+// loadPackage returns a rule set for every struct of the package in the given directory which
+// has at least one field with a transform tag, sorted by struct name.
+func loadPackage(dir string) ([]*transformation.RuleSet, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []*transformation.RuleSet
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+
+					fields, err := structFieldRules(st)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %v", fset.Position(ts.Pos()), err)
+					}
+
+					if len(fields) == 0 {
+						continue
+					}
+
+					rs, err := transformation.NewRuleSet(ts.Name.Name, fields...)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %v", fset.Position(ts.Pos()), err)
+					}
+					sets = append(sets, rs)
+				}
+			}
+		}
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Name < sets[j].Name
+	})
+
+	return sets, nil
+}
+
+// structFieldRules returns the rules of the exported fields of the struct which have a
+// transform tag.
+func structFieldRules(st *ast.StructType) ([]transformation.FieldRules, error) {
+	var fields []transformation.FieldRules
+	for _, field := range st.Fields.List {
+		if field.Tag == nil || len(field.Names) == 0 {
+			continue
+		}
+
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			sf := reflect.StructField{Name: name.Name, Tag: reflect.StructTag(tag)}
+			if rules, ok := transformation.FieldRulesFromTag(sf); ok {
+				fields = append(fields, rules)
+			}
+		}
+	}
+
+	return fields, nil
+}
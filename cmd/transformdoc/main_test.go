@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRunMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	err := run(&buf, "markdown", []string{"testdata/signup.json"}, []string{"testdata/models"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `## Signup
+
+| Field | JSON name | Pipeline | Default | Errors |
+| --- | --- | --- | --- | --- |
+| Phone | phone | Trim → Default(value="unknown") | "unknown" |  |
+
+## Audit
+
+| Field | JSON name | Pipeline | Default | Errors |
+| --- | --- | --- | --- | --- |
+| CreatedBy | created_by | Trim |  |  |
+
+## Customer
+
+| Field | JSON name | Pipeline | Default | Errors |
+| --- | --- | --- | --- | --- |
+| Name | name | Trim → UpperCase |  |  |
+| Email | Email | Trim → DownCase |  |  |
+| Password |  | Trim |  |  |
+`, buf.String())
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if !assert.NoError(t, run(&buf, "schema", nil, []string{"testdata/models"})) {
+		return
+	}
+
+	var schemas map[string]map[string]map[string][]string
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &schemas)) {
+		assert.Equal(t, map[string]map[string]map[string][]string{
+			"Audit": {"created_by": {"x-transform": {"Trim"}}},
+			"Customer": {
+				"name":     {"x-transform": {"Trim", "UpperCase"}},
+				"Email":    {"x-transform": {"Trim", "DownCase"}},
+				"Password": {"x-transform": {"Trim"}},
+			},
+		}, schemas)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		files  []string
+		dirs   []string
+		err    string
+	}{
+		{name: "unknown format", format: "pdf", err: `unknown format "pdf"`},
+		{name: "missing rule file", format: "html", files: []string{"testdata/missing.json"}, err: "testdata/missing.json: open testdata/missing.json: no such file or directory"},
+		{name: "missing package", format: "html", dirs: []string{"testdata/missing"}, err: "open testdata/missing: no such file or directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.EqualError(t, run(&buf, tt.format, tt.files, tt.dirs), tt.err)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+type (
+	Audit struct {
+		CreatedBy string `json:"created_by" transform:"trim"`
+	}
+
+	Customer struct {
+		Audit
+		Name     string `json:"name" transform:"trim|upper_case"`
+		Email    string `transform:"trim|down_case"`
+		Password string `json:"-" transform:"trim"`
+		Notes    string `json:"notes" transform:"-"`
+		internal string `transform:"trim"`
+	}
+
+	Untagged struct {
+		Name string `json:"name"`
+	}
+)
@@ -0,0 +1,605 @@
+package transformation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type (
+	// Predicate reports whether Filter keeps an element, or whether Reject drops it.
+	Predicate func(v interface{}) (bool, error)
+
+	// KeyFunc returns the key UniqueBy and SortBy compare an element by.
+	KeyFunc func(v interface{}) (interface{}, error)
+
+	// collectionTransformer reorders or drops the elements of a slice, an array or the values
+	// of a map. Slices keep their type and arrays become slices of their element type. Maps
+	// keep their type if the operation does not depend on the order of the elements; otherwise
+	// their values, sorted by key, become a slice.
+	collectionTransformer struct {
+		name        string
+		description string
+		params      map[string]interface{}
+		keepsMap    bool
+		// indexes returns the indexes of the elements in the result, in their new order.
+		indexes func(values []interface{}) ([]int, error)
+	}
+
+	flattenTransformer struct{}
+
+	chunkTransformer struct {
+		size int
+	}
+
+	// collection is a slice, an array or a map being transformed.
+	collection struct {
+		value reflect.Value
+		// keys are the sorted keys of a map, nil for slices and arrays.
+		keys      []reflect.Value
+		elements  []reflect.Value
+		values    []interface{}
+		sliceType reflect.Type
+	}
+)
+
+// Filter returns a transformer which keeps the elements the predicate is true for.
+func Filter(predicate Predicate) *collectionTransformer {
+	return &collectionTransformer{
+		name:        "Filter",
+		description: "Keeps the elements the predicate is true for.",
+		keepsMap:    true,
+		indexes: func(values []interface{}) ([]int, error) {
+			return filterIndexes(values, predicate, true)
+		},
+	}
+}
+
+// Reject returns a transformer which drops the elements the predicate is true for, the
+// opposite of Filter.
+func Reject(predicate Predicate) *collectionTransformer {
+	return &collectionTransformer{
+		name:        "Reject",
+		description: "Drops the elements the predicate is true for.",
+		keepsMap:    true,
+		indexes: func(values []interface{}) ([]int, error) {
+			return filterIndexes(values, predicate, false)
+		},
+	}
+}
+
+// Map returns a transformer which replaces every element with the result of the function.
+// It is a shorthand for Each(By(fn)).
+func Map(fn TransformFunc) *eachTransformer {
+	return Each(By(fn))
+}
+
+// Unique returns a transformer which drops the elements equal to an element before them.
+// The elements must be comparable.
+func Unique() *collectionTransformer {
+	return UniqueBy(nil)
+}
+
+// UniqueBy returns a transformer which drops the elements with the same key as an element
+// before them. The keys must be comparable.
+func UniqueBy(key KeyFunc) *collectionTransformer {
+	name := "UniqueBy"
+	if key == nil {
+		name = "Unique"
+	}
+
+	return &collectionTransformer{
+		name:        name,
+		description: "Drops the duplicated elements, keeping the first one.",
+		keepsMap:    true,
+		indexes: func(values []interface{}) ([]int, error) {
+			keys, err := elementKeys(values, key)
+			if err != nil {
+				return nil, err
+			}
+
+			seen := make(map[interface{}]bool, len(keys))
+			indexes := make([]int, 0, len(keys))
+			for i, k := range keys {
+				if !isHashable(k) {
+					return nil, fmt.Errorf("%T is not comparable", k)
+				}
+
+				if !seen[k] {
+					seen[k] = true
+					indexes = append(indexes, i)
+				}
+			}
+
+			return indexes, nil
+		},
+	}
+}
+
+// Sort returns a transformer which sorts the elements in ascending order. Numbers, strings,
+// booleans and times can be sorted; nil comes first.
+func Sort() *collectionTransformer {
+	return SortBy(nil)
+}
+
+// SortBy returns a transformer which sorts the elements in ascending order of their keys.
+// Elements with equal keys keep their order.
+func SortBy(key KeyFunc) *collectionTransformer {
+	name := "SortBy"
+	if key == nil {
+		name = "Sort"
+	}
+
+	return &collectionTransformer{
+		name:        name,
+		description: "Sorts the elements in ascending order.",
+		indexes: func(values []interface{}) ([]int, error) {
+			keys, err := elementKeys(values, key)
+			if err != nil {
+				return nil, err
+			}
+
+			indexes := sequentialIndexes(0, len(values))
+			var cmpErr error
+			sort.SliceStable(indexes, func(i, j int) bool {
+				c, err := compareValues(keys[indexes[i]], keys[indexes[j]])
+				if err != nil && cmpErr == nil {
+					cmpErr = err
+				}
+
+				return c < 0
+			})
+			if cmpErr != nil {
+				return nil, cmpErr
+			}
+
+			return indexes, nil
+		},
+	}
+}
+
+// ReverseOrder returns a transformer which reverses the order of the elements.
+func ReverseOrder() *collectionTransformer {
+	return &collectionTransformer{
+		name:        "ReverseOrder",
+		description: "Reverses the order of the elements.",
+		indexes: func(values []interface{}) ([]int, error) {
+			indexes := make([]int, len(values))
+			for i := range indexes {
+				indexes[i] = len(values) - 1 - i
+			}
+
+			return indexes, nil
+		},
+	}
+}
+
+// Take returns a transformer which keeps the first n elements.
+func Take(n int) *collectionTransformer {
+	if n < 0 {
+		n = 0
+	}
+
+	return &collectionTransformer{
+		name:        "Take",
+		description: "Keeps the first elements.",
+		params:      map[string]interface{}{"n": n},
+		indexes: func(values []interface{}) ([]int, error) {
+			if n > len(values) {
+				return sequentialIndexes(0, len(values)), nil
+			}
+
+			return sequentialIndexes(0, n), nil
+		},
+	}
+}
+
+// Skip returns a transformer which drops the first n elements.
+func Skip(n int) *collectionTransformer {
+	if n < 0 {
+		n = 0
+	}
+
+	return &collectionTransformer{
+		name:        "Skip",
+		description: "Drops the first elements.",
+		params:      map[string]interface{}{"n": n},
+		indexes: func(values []interface{}) ([]int, error) {
+			if n > len(values) {
+				return nil, nil
+			}
+
+			return sequentialIndexes(n, len(values)), nil
+		},
+	}
+}
+
+// Flatten returns a transformer which replaces the elements which are slices or arrays with
+// their own elements, e.g. [][]string{{"a"}, {"b", "c"}} becomes []string{"a", "b", "c"}.
+func Flatten() flattenTransformer {
+	return flattenTransformer{}
+}
+
+// Chunk returns a transformer which splits the elements into slices of the given size; the
+// last one may be shorter, e.g. []int{1, 2, 3} becomes [][]int{{1, 2}, {3}} with a size of 2.
+func Chunk(size int) chunkTransformer {
+	if size < 1 {
+		size = 1
+	}
+
+	return chunkTransformer{size: size}
+}
+
+func (t *collectionTransformer) Transform(from interface{}) (interface{}, error) {
+	c, err := newCollection(from)
+	if c == nil || err != nil {
+		return nil, err
+	}
+
+	indexes, err := t.indexes(c.values)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.keepsMap && c.keys != nil {
+		return c.mapOf(indexes).Interface(), nil
+	}
+
+	return c.sliceOf(indexes).Interface(), nil
+}
+
+func (t *collectionTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if in.Kind() == reflect.Map && t.keepsMap {
+		return in, nil
+	}
+
+	return collectionSliceType(in)
+}
+
+func (t *collectionTransformer) Name() string {
+	return t.name
+}
+
+func (t *collectionTransformer) Description() string {
+	return t.description
+}
+
+func (t *collectionTransformer) Params() map[string]interface{} {
+	return t.params
+}
+
+func (t *collectionTransformer) ErrorConditions() []string {
+	return []string{"Fails when the value is not a slice, array or map."}
+}
+
+func (t flattenTransformer) Transform(from interface{}) (interface{}, error) {
+	c, err := newCollection(from)
+	if c == nil || err != nil {
+		return nil, err
+	}
+
+	if elem := c.sliceType.Elem(); elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+		sl := reflect.MakeSlice(reflect.SliceOf(elem.Elem()), 0, len(c.elements))
+		for _, el := range c.elements {
+			for i := 0; i < el.Len(); i++ {
+				sl = reflect.Append(sl, el.Index(i))
+			}
+		}
+
+		return sl.Interface(), nil
+	}
+
+	values := make([]interface{}, 0, len(c.values))
+	sameType := true
+	for _, v := range c.values {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			values = append(values, v)
+			sameType = sameType && v != nil
+			continue
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			ev := rv.Index(i).Interface()
+			values = append(values, ev)
+			sameType = sameType && ev != nil
+		}
+	}
+
+	if !sameType || len(values) == 0 {
+		return values, nil
+	}
+
+	return toConcreteSlice(values), nil
+}
+
+func (t flattenTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	st, err := collectionSliceType(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if elem := st.Elem(); elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+		return reflect.SliceOf(elem.Elem()), nil
+	}
+
+	return nil, nil
+}
+
+func (t flattenTransformer) Name() string {
+	return "Flatten"
+}
+
+func (t flattenTransformer) Description() string {
+	return "Replaces the elements which are slices or arrays with their own elements."
+}
+
+func (t flattenTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t flattenTransformer) ErrorConditions() []string {
+	return []string{"Fails when the value is not a slice, array or map."}
+}
+
+func (t chunkTransformer) Transform(from interface{}) (interface{}, error) {
+	c, err := newCollection(from)
+	if c == nil || err != nil {
+		return nil, err
+	}
+
+	chunks := reflect.MakeSlice(reflect.SliceOf(c.sliceType), 0, (len(c.elements)+t.size-1)/t.size)
+	for i := 0; i < len(c.elements); i += t.size {
+		end := i + t.size
+		if end > len(c.elements) {
+			end = len(c.elements)
+		}
+
+		chunks = reflect.Append(chunks, c.sliceOf(sequentialIndexes(i, end)))
+	}
+
+	return chunks.Interface(), nil
+}
+
+func (t chunkTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	st, err := collectionSliceType(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflect.SliceOf(st), nil
+}
+
+func (t chunkTransformer) Name() string {
+	return "Chunk"
+}
+
+func (t chunkTransformer) Description() string {
+	return "Splits the elements into slices of the given size."
+}
+
+func (t chunkTransformer) Params() map[string]interface{} {
+	return map[string]interface{}{"size": t.size}
+}
+
+func (t chunkTransformer) ErrorConditions() []string {
+	return []string{"Fails when the value is not a slice, array or map."}
+}
+
+// newCollection returns the collection the value references, or nil if it is nil.
+func newCollection(from interface{}) (*collection, error) {
+	from, isNil := indirect(from)
+	if isNil {
+		return nil, nil
+	}
+
+	c := &collection{value: reflect.ValueOf(from)}
+	switch c.value.Kind() {
+	case reflect.Slice:
+		c.sliceType = c.value.Type()
+	case reflect.Array:
+		c.sliceType = reflect.SliceOf(c.value.Type().Elem())
+	case reflect.Map:
+		c.sliceType = reflect.SliceOf(c.value.Type().Elem())
+		c.keys = sortedMapKeys(c.value)
+	default:
+		return nil, fmt.Errorf("must be a slice, array or map but got %T", from)
+	}
+
+	if c.keys != nil {
+		c.elements = make([]reflect.Value, len(c.keys))
+		for i, k := range c.keys {
+			c.elements[i] = c.value.MapIndex(k)
+		}
+	} else {
+		c.elements = make([]reflect.Value, c.value.Len())
+		for i := range c.elements {
+			c.elements[i] = c.value.Index(i)
+		}
+	}
+
+	c.values = make([]interface{}, len(c.elements))
+	for i, el := range c.elements {
+		c.values[i] = el.Interface()
+	}
+
+	return c, nil
+}
+
+// sliceOf returns a slice of the elements with the given indexes.
+func (c *collection) sliceOf(indexes []int) reflect.Value {
+	sl := reflect.MakeSlice(c.sliceType, len(indexes), len(indexes))
+	for i, index := range indexes {
+		sl.Index(i).Set(c.elements[index])
+	}
+
+	return sl
+}
+
+// mapOf returns a map of the same type with the entries of the given indexes.
+func (c *collection) mapOf(indexes []int) reflect.Value {
+	m := reflect.MakeMapWithSize(c.value.Type(), len(indexes))
+	for _, index := range indexes {
+		m.SetMapIndex(c.keys[index], c.elements[index])
+	}
+
+	return m
+}
+
+// collectionSliceType returns the type of the slice the elements of the collection type are
+// collected into.
+func collectionSliceType(in reflect.Type) (reflect.Type, error) {
+	switch in.Kind() {
+	case reflect.Slice:
+		return in, nil
+	case reflect.Array, reflect.Map:
+		return reflect.SliceOf(in.Elem()), nil
+	default:
+		return nil, fmt.Errorf("expected a slice, array or map but got %s", in)
+	}
+}
+
+func filterIndexes(values []interface{}, predicate Predicate, keep bool) ([]int, error) {
+	indexes := make([]int, 0, len(values))
+	for i, v := range values {
+		ok, err := predicate(v)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %v", i, err)
+		}
+
+		if ok == keep {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes, nil
+}
+
+// elementKeys returns the keys of the values, or the values themselves if key is nil.
+func elementKeys(values []interface{}, key KeyFunc) ([]interface{}, error) {
+	if key == nil {
+		return values, nil
+	}
+
+	keys := make([]interface{}, len(values))
+	for i, v := range values {
+		k, err := key(v)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %v", i, err)
+		}
+		keys[i] = k
+	}
+
+	return keys, nil
+}
+
+func sequentialIndexes(from, to int) []int {
+	indexes := make([]int, to-from)
+	for i := range indexes {
+		indexes[i] = from + i
+	}
+
+	return indexes
+}
+
+// sortedMapKeys returns the keys of the map in ascending order. Keys which cannot be compared
+// are ordered by their formatted value.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i].Interface(), keys[j].Interface()
+		if c, err := compareValues(a, b); err == nil {
+			return c < 0
+		}
+
+		return fmt.Sprint(a) < fmt.Sprint(b)
+	})
+
+	return keys
+}
+
+// compareValues returns -1, 0 or 1 if a is less than, equal to or greater than b. Numbers of
+// any kind, strings, booleans and times can be compared; nil is less than any other value.
+func compareValues(a, b interface{}) (int, error) {
+	a, aNil := indirect(a)
+	b, bNil := indirect(b)
+	switch {
+	case aNil && bNil:
+		return 0, nil
+	case aNil:
+		return -1, nil
+	case bNil:
+		return 1, nil
+	}
+
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			switch {
+			case at.Before(bt):
+				return -1, nil
+			case at.After(bt):
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isNumberKind(av.Kind()) && isNumberKind(bv.Kind()):
+		return compareNumbers(av, bv), nil
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return compareOrdered(av.String() < bv.String(), av.String() > bv.String()), nil
+	case av.Kind() == reflect.Bool && bv.Kind() == reflect.Bool:
+		return compareOrdered(!av.Bool() && bv.Bool(), av.Bool() && !bv.Bool()), nil
+	default:
+		return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+	}
+}
+
+func compareNumbers(a, b reflect.Value) int {
+	switch {
+	case isIntKind(a.Kind()) && isIntKind(b.Kind()):
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int())
+	case isUintKind(a.Kind()) && isUintKind(b.Kind()):
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	default:
+		af := convertNumber(a, reflect.TypeOf(float64(0))).Float()
+		bf := convertNumber(b, reflect.TypeOf(float64(0))).Float()
+
+		return compareOrdered(af < bf, af > bf)
+	}
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
@@ -0,0 +1,198 @@
+package transformation_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Tags []string
+
+func isBlank(v interface{}) (bool, error) {
+	s, ok := v.(string)
+	if !ok {
+		return false, errors.New("not a string")
+	}
+
+	return strings.TrimSpace(s) == "", nil
+}
+
+func TestFilterAndReject(t *testing.T) {
+	notBlank := func(v interface{}) (bool, error) {
+		blank, err := isBlank(v)
+
+		return !blank, err
+	}
+
+	out, err := transformation.Filter(notBlank).Transform(Tags{"a", " ", "b"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Tags{"a", "b"}, out)
+	}
+
+	out, err = transformation.Reject(isBlank).Transform([3]string{"a", "", "c"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "c"}, out)
+	}
+
+	out, err = transformation.Reject(isBlank).Transform(map[string]string{"a": "x", "b": ""})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"a": "x"}, out)
+	}
+
+	_, err = transformation.Filter(notBlank).Transform([]interface{}{"a", 1})
+	assert.EqualError(t, err, "element 1: not a string")
+
+	_, err = transformation.Filter(notBlank).Transform("a")
+	assert.EqualError(t, err, "must be a slice, array or map but got string")
+
+	out, err = transformation.Filter(notBlank).Transform(nil)
+	if assert.NoError(t, err) {
+		assert.Nil(t, out)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	out, err := transformation.Unique().Transform([]int{3, 1, 3, 2, 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{3, 1, 2}, out)
+	}
+
+	out, err = transformation.UniqueBy(func(v interface{}) (interface{}, error) {
+		return strings.ToLower(v.(string)), nil
+	}).Transform([]string{"Go", "go", "Rust"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"Go", "Rust"}, out)
+	}
+
+	out, err = transformation.Unique().Transform(map[string]int{"c": 1, "a": 1, "b": 2})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, out)
+	}
+
+	_, err = transformation.Unique().Transform([][]int{{1}})
+	assert.EqualError(t, err, "[]int is not comparable")
+
+	type key struct{ V interface{} }
+	_, err = transformation.Unique().Transform([]interface{}{key{[]int{1}}, key{[]int{1}}})
+	assert.EqualError(t, err, "transformation_test.key is not comparable")
+}
+
+func TestSortBy(t *testing.T) {
+	out, err := transformation.Sort().Transform([]interface{}{2.5, 1, nil, uint8(2)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{nil, 1, uint8(2), 2.5}, out)
+	}
+
+	type person struct {
+		Name string
+		Born time.Time
+	}
+	people := []person{
+		{Name: "b", Born: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "a", Born: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "c", Born: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	out, err = transformation.SortBy(func(v interface{}) (interface{}, error) {
+		return v.(person).Born, nil
+	}).Transform(people)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []person{people[1], people[0], people[2]}, out)
+	}
+
+	out, err = transformation.Sort().Transform(map[string]string{"x": "b", "y": "a"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b"}, out)
+	}
+
+	_, err = transformation.Sort().Transform([]interface{}{"a", 1})
+	assert.EqualError(t, err, "cannot compare int with string")
+}
+
+func TestReverseOrderTakeSkip(t *testing.T) {
+	out, err := transformation.ReverseOrder().Transform(Tags{"a", "b", "c"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Tags{"c", "b", "a"}, out)
+	}
+
+	out, err = transformation.Take(2).Transform([]int{1, 2, 3})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{1, 2}, out)
+	}
+
+	out, err = transformation.Take(5).Transform(map[int]string{2: "b", 1: "a"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b"}, out)
+	}
+
+	out, err = transformation.Skip(1).Transform([2]int{1, 2})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{2}, out)
+	}
+
+	out, err = transformation.Skip(3).Transform([]int{1, 2})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{}, out)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	out, err := transformation.Flatten().Transform([][]string{{"a"}, {}, {"b", "c"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b", "c"}, out)
+	}
+
+	out, err = transformation.Flatten().Transform([]interface{}{[]int{1, 2}, 3, [1]int{4}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{1, 2, 3, 4}, out)
+	}
+
+	out, err = transformation.Flatten().Transform([]interface{}{[]interface{}{"a", nil}, 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{"a", nil, 1}, out)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	out, err := transformation.Chunk(2).Transform(Tags{"a", "b", "c"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Tags{{"a", "b"}, {"c"}}, out)
+	}
+
+	out, err = transformation.Chunk(2).Transform([]int{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, [][]int{}, out)
+	}
+}
+
+func TestCollectionPipeline(t *testing.T) {
+	ts, err := transformation.ParsePipeline("each(trim|down_case)|unique|sort|skip(1)|take(2)|chunk(1)|flatten|reverse_order")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var to []string
+	err = transformation.Transform([]string{" b", "A ", "c", "a", "d"}, &to, ts...)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"c", "b"}, to)
+	}
+
+	out, err := transformation.Check(reflect.TypeOf([]string{}), ts...)
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf([]string{}), out)
+	}
+
+	out, err = transformation.Check(reflect.TypeOf(map[string]int{}), transformation.Unique(), transformation.Chunk(2))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf([][]int{}), out)
+	}
+
+	_, err = transformation.ParsePipeline("chunk(0)")
+	assert.EqualError(t, err, `chunk: invalid size "0"`)
+
+	_, err = transformation.ParsePipeline("take(-1)")
+	assert.EqualError(t, err, `take: invalid number of elements "-1"`)
+}
@@ -0,0 +1,380 @@
+package transformation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// ComputedField is a rule which derives the value of a struct field from other fields
+	// of the same struct.
+	ComputedField struct {
+		field interface{}
+		fn    interface{}
+		deps  []interface{}
+	}
+
+	// rulePlan holds the validated rules of a TransformStruct call and the order they have
+	// to be applied in.
+	rulePlan struct {
+		rules []Rule
+		names []string
+		order []int
+		preds [][]int
+	}
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Computed returns a rule which sets the given struct field to the value returned by fn.
+// fn receives the pointer to the struct being transformed and returns the computed value
+// and an error, e.g. func(p *Person) (interface{}, error). The rule is passed to
+// TransformStructRules and applied after every rule writing to one of the given dependencies.
+func Computed(field interface{}, fn interface{}, deps ...interface{}) *ComputedField {
+	return &ComputedField{
+		field: field,
+		fn:    fn,
+		deps:  deps,
+	}
+}
+
+func (c *ComputedField) fieldName(structValue reflect.Value) (string, error) {
+	name, err := fieldName(structValue, c.field, "computed")
+	if err != nil {
+		return "", err
+	}
+
+	ft := reflect.TypeOf(c.fn)
+	if ft == nil || ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 2 || ft.Out(1) != errorType {
+		return "", fmt.Errorf("computed field %s expects a func(%s) (interface{}, error) but got %T", name, structValue.Addr().Type(), c.fn)
+	}
+	if !structValue.Addr().Type().AssignableTo(ft.In(0)) {
+		return "", fmt.Errorf("computed field %s expects a func(%s) (interface{}, error) but got %T", name, structValue.Addr().Type(), c.fn)
+	}
+
+	for _, dep := range c.deps {
+		if _, err := fieldName(structValue, dep, "dependency"); err != nil {
+			return "", err
+		}
+	}
+
+	return name, nil
+}
+
+func (c *ComputedField) sources() []interface{} {
+	return c.deps
+}
+
+func (c *ComputedField) targets() []interface{} {
+	return []interface{}{c.field}
+}
+
+func (c *ComputedField) dependencies() []interface{} {
+	return c.deps
+}
+
+func (c *ComputedField) pipeline() []Transformer {
+	return nil
+}
+
+func (c *ComputedField) rebase(fn func(ptr interface{}) interface{}) Rule {
+	deps := make([]interface{}, len(c.deps))
+	for i, dep := range c.deps {
+		deps[i] = fn(dep)
+	}
+
+	return &ComputedField{
+		field: fn(c.field),
+		fn:    c.fn,
+		deps:  deps,
+	}
+}
+
+func (c *ComputedField) apply(e *execution, structPtr reflect.Value) error {
+	out := reflect.ValueOf(c.fn).Call([]reflect.Value{structPtr})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return err
+	}
+
+	return e.transformTo(out[0].Interface(), c.field, nil)
+}
+
+// planRules validates the given rules against the struct and orders them so that every
+// rule comes after the rules writing to its dependencies. Rules without dependencies keep
+// their declaration order.
+func planRules(structValue reflect.Value, rules []Rule) (*rulePlan, error) {
+	p := &rulePlan{
+		rules: rules,
+		names: make([]string, len(rules)),
+		preds: make([][]int, len(rules)),
+	}
+
+	for i, rule := range rules {
+		name, err := rule.fieldName(structValue)
+		if err != nil {
+			return nil, err
+		}
+		p.names[i] = name
+	}
+
+	for i, rule := range rules {
+		for _, dep := range rule.dependencies() {
+			for j, other := range rules {
+				if i == j {
+					continue
+				}
+				for _, target := range other.targets() {
+					if samePointer(dep, target) {
+						p.preds[i] = append(p.preds[i], j)
+					}
+				}
+			}
+		}
+	}
+
+	done := make([]bool, len(rules))
+	for len(p.order) < len(rules) {
+		next := -1
+		for i := range rules {
+			if !done[i] && p.ready(i, done) {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			var names []string
+			for i := range rules {
+				if !done[i] {
+					names = append(names, p.names[i])
+				}
+			}
+
+			return nil, fmt.Errorf("dependency cycle between fields: %s", strings.Join(names, ", "))
+		}
+
+		done[next] = true
+		p.order = append(p.order, next)
+	}
+
+	return p, nil
+}
+
+// run applies the rules in order to the struct the given pointer points to, running up to
+// limit rules at the same time. If rec is not nil, the changes made by the rules are recorded.
+func (p *rulePlan) run(e *execution, structPtr reflect.Value, rec *changeRecorder, limit int) error {
+	failures := make([]error, len(p.rules))
+	changes := make([][]Change, len(p.rules))
+	nodes := make([]*TraceNode, len(p.rules))
+
+	step := func(i int) {
+		if dep, ok := p.failedDependency(i, failures); ok {
+			failures[i] = fmt.Errorf("dependency %s failed", p.names[dep])
+			return
+		}
+
+		var before []interface{}
+		if rec != nil {
+			before = rec.snapshot(p.rules[i])
+		}
+
+		fe, node := e.field(p.names[i]).startTrace(traceField, p.names[i], nil)
+		if node != nil {
+			node.Input = traceValue(p.rules[i].sources())
+		}
+		nodes[i] = node
+
+		start := time.Now()
+		err := p.rules[i].apply(fe, structPtr)
+		node.finish(traceValue(p.rules[i].targets()), err)
+		if e.metrics != nil {
+			e.metrics.ObserveField(structName(structPtr)+"."+p.names[i], time.Since(start), err)
+		}
+		if rec != nil {
+			changes[i] = rec.diff(structPtr, p.names[i], p.rules[i], before)
+		}
+
+		failures[i] = err
+	}
+
+	if limit > 1 {
+		p.runConcurrently(limit, func(i int) {
+			// a panic on a goroutine would crash the process, so it fails the rule instead
+			defer func() {
+				if r := recover(); r != nil {
+					failures[i] = fmt.Errorf("transformation panicked: %v", r)
+				}
+			}()
+			step(i)
+		})
+		if e.trace != nil {
+			e.trace.sortChildren(p.order, nodes)
+		}
+	} else {
+		for _, i := range p.order {
+			step(i)
+		}
+	}
+
+	errs := Errors{}
+	for _, i := range p.order {
+		if rec != nil {
+			rec.changes = append(rec.changes, changes[i]...)
+		}
+		if failures[i] != nil {
+			errs[p.names[i]] = failures[i]
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// runConcurrently calls step for every rule on up to limit goroutines. A rule is started once
+// the rules it depends on and the earlier rules it conflicts with are done. Among the rules
+// ready to start, the ones coming first in the plan order are started first.
+func (p *rulePlan) runConcurrently(limit int, step func(i int)) {
+	waiting := make([]int, len(p.rules))
+	next := make([][]int, len(p.rules))
+	for _, i := range p.order {
+		for _, pred := range p.waits(i) {
+			waiting[i]++
+			next[pred] = append(next[pred], i)
+		}
+	}
+
+	position := make([]int, len(p.rules))
+	var ready []int
+	for pos, i := range p.order {
+		position[i] = pos
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	done := make(chan int)
+	running := 0
+	for finished := 0; finished < len(p.rules); finished++ {
+		for running < limit && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				step(i)
+				done <- i
+			}()
+		}
+
+		i := <-done
+		running--
+		for _, n := range next[i] {
+			if waiting[n]--; waiting[n] == 0 {
+				ready = append(ready, n)
+			}
+		}
+		sort.Slice(ready, func(a, b int) bool {
+			return position[ready[a]] < position[ready[b]]
+		})
+	}
+}
+
+// waits returns the rules the i-th rule has to wait for when the rules run concurrently: the
+// rules it depends on and the rules coming before it in the plan order which write a field
+// it reads or writes, or read a field it writes.
+func (p *rulePlan) waits(i int) []int {
+	waits := append([]int(nil), p.preds[i]...)
+	for _, j := range p.order {
+		if j == i {
+			break
+		}
+
+		if conflicts(p.rules[j], p.rules[i]) && !containsInt(waits, j) {
+			waits = append(waits, j)
+		}
+	}
+
+	return waits
+}
+
+// conflicts reports whether one of the rules writes a field the other one reads or writes.
+func conflicts(a, b Rule) bool {
+	return overlaps(a.targets(), b.targets()) ||
+		overlaps(a.targets(), b.sources()) ||
+		overlaps(a.sources(), b.targets())
+}
+
+func overlaps(a, b []interface{}) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if samePointer(x, y) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rebase returns a copy of the plan with every rule rebased with fn.
+func (p *rulePlan) rebase(fn func(ptr interface{}) interface{}) *rulePlan {
+	rules := make([]Rule, len(p.rules))
+	for i, rule := range p.rules {
+		rules[i] = rule.rebase(fn)
+	}
+
+	return &rulePlan{
+		rules: rules,
+		names: p.names,
+		order: p.order,
+		preds: p.preds,
+	}
+}
+
+// ready reports whether all the rules the i-th rule depends on are done.
+func (p *rulePlan) ready(i int, done []bool) bool {
+	for _, pred := range p.preds[i] {
+		if !done[pred] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// failedDependency returns the index of the first rule the i-th rule depends on which failed.
+func (p *rulePlan) failedDependency(i int, failures []error) (int, bool) {
+	for _, pred := range p.preds[i] {
+		if failures[pred] != nil {
+			return pred, true
+		}
+	}
+
+	return 0, false
+}
+
+// samePointer reports whether both values are pointers of the same type to the same address.
+func samePointer(a, b interface{}) bool {
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+	if av.Kind() != reflect.Ptr || bv.Kind() != reflect.Ptr {
+		return false
+	}
+
+	return av.Type() == bv.Type() && av.Pointer() == bv.Pointer()
+}
@@ -0,0 +1,191 @@
+package transformation_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type Customer struct {
+	FirstName string
+	LastName  string
+	FullName  string
+	Initials  string
+}
+
+func TestComputed(t *testing.T) {
+	c := Customer{FirstName: "  John ", LastName: " Doe  "}
+	err := transformation.TransformStructRules(
+		&c,
+		transformation.Computed(&c.Initials, func(c *Customer) (interface{}, error) {
+			return fmt.Sprintf("%c%c", c.FullName[0], c.LastName[0]), nil
+		}, &c.FullName),
+		transformation.Computed(&c.FullName, func(c *Customer) (interface{}, error) {
+			return c.FirstName + " " + c.LastName, nil
+		}, &c.FirstName, &c.LastName),
+		transformation.Field(&c.FirstName, &c.FirstName, transformation.Trim),
+		transformation.Field(&c.LastName, &c.LastName, transformation.Trim),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "John Doe", c.FullName)
+		assert.Equal(t, "JD", c.Initials)
+	}
+}
+
+func TestComputedError(t *testing.T) {
+	c := Customer{FirstName: "John"}
+	err := transformation.TransformStructRules(
+		&c,
+		transformation.Computed(&c.FullName, func(c *Customer) (interface{}, error) {
+			return nil, errors.New("last name is missing")
+		}, &c.FirstName, &c.LastName),
+		transformation.Computed(&c.Initials, func(c *Customer) (interface{}, error) {
+			return "JD", nil
+		}, &c.FullName),
+	)
+	if assert.Error(t, err) {
+		assert.Equal(t, "FullName: last name is missing; Initials: dependency FullName failed.", err.Error())
+		assert.Zero(t, c.FullName)
+		assert.Zero(t, c.Initials)
+	}
+}
+
+func TestComputedCycle(t *testing.T) {
+	c := Customer{}
+	fn := func(c *Customer) (interface{}, error) {
+		return "", nil
+	}
+	err := transformation.TransformStructRules(
+		&c,
+		transformation.Computed(&c.FullName, fn, &c.Initials),
+		transformation.Computed(&c.Initials, fn, &c.FullName),
+		transformation.Field(&c.FirstName, &c.FirstName, transformation.Trim),
+	)
+	if assert.Error(t, err) {
+		assert.Equal(t, "dependency cycle between fields: FullName, Initials", err.Error())
+	}
+}
+
+func TestComputedInvalidFunc(t *testing.T) {
+	c := Customer{}
+	err := transformation.TransformStructRules(
+		&c,
+		transformation.Computed(&c.FullName, func(c *Person) (interface{}, error) {
+			return "", nil
+		}),
+	)
+	assert.Error(t, err)
+}
+
+func TestConcurrency(t *testing.T) {
+	var running, maxRunning int32
+	slowTrim := transformation.By(func(from interface{}) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		return strings.TrimSpace(from.(string)), nil
+	})
+
+	c := Customer{FirstName: "  John ", LastName: " Doe  ", Initials: " x "}
+	var changes transformation.ChangeSet
+	err := transformation.TransformStructWithOptions(
+		&c,
+		[]transformation.Option{transformation.Concurrency(2), transformation.RecordChanges(&changes)},
+		transformation.Computed(&c.FullName, func(c *Customer) (interface{}, error) {
+			return c.FirstName + " " + c.LastName, nil
+		}, &c.FirstName, &c.LastName),
+		transformation.Field(&c.FirstName, &c.FirstName, slowTrim),
+		transformation.Field(&c.LastName, &c.LastName, slowTrim),
+		transformation.Field(&c.Initials, &c.Initials, slowTrim),
+		transformation.Field(&c.FirstName, &c.Initials, transformation.UpperCase),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Customer{FirstName: "John", LastName: "Doe", FullName: "John Doe", Initials: "JOHN"}, c)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+
+		var paths []string
+		for _, change := range changes {
+			paths = append(paths, change.Path)
+		}
+		assert.Equal(t, []string{"FirstName", "LastName", "FullName", "Initials", "Initials"}, paths)
+	}
+}
+
+func TestConcurrencyErrors(t *testing.T) {
+	fail := transformation.By(func(from interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("%v is invalid", from)
+	})
+
+	for i := 0; i < 20; i++ {
+		c := Customer{FirstName: "John", LastName: "Doe"}
+		err := transformation.TransformStructWithOptions(
+			&c,
+			[]transformation.Option{transformation.Concurrency(4)},
+			transformation.Field(&c.FirstName, &c.FirstName, fail),
+			transformation.Field(&c.LastName, &c.LastName, fail),
+			transformation.Computed(&c.FullName, func(c *Customer) (interface{}, error) {
+				return c.FirstName + " " + c.LastName, nil
+			}, &c.FirstName),
+			transformation.Computed(&c.Initials, func(c *Customer) (interface{}, error) {
+				return "JD", nil
+			}, &c.FullName),
+		)
+		assert.Equal(t, transformation.Errors{
+			"FirstName": errors.New("John is invalid"),
+			"LastName":  errors.New("Doe is invalid"),
+			"FullName":  errors.New("dependency FirstName failed"),
+			"Initials":  errors.New("dependency FullName failed"),
+		}, err)
+	}
+}
+
+func TestConcurrencyPanics(t *testing.T) {
+	c := Customer{FirstName: "John", LastName: "Doe"}
+	err := transformation.TransformStructWithOptions(
+		&c,
+		[]transformation.Option{transformation.Concurrency(2)},
+		transformation.Field(&c.FirstName, &c.FirstName, transformation.By(func(from interface{}) (interface{}, error) {
+			panic("boom")
+		})),
+		transformation.Field(&c.LastName, &c.LastName, transformation.UpperCase),
+		transformation.Computed(&c.FullName, func(c *Customer) (interface{}, error) {
+			return c.FirstName + " " + c.LastName, nil
+		}, &c.FirstName),
+	)
+	assert.Equal(t, transformation.Errors{
+		"FirstName": errors.New("transformation panicked: boom"),
+		"FullName":  errors.New("dependency FirstName failed"),
+	}, err)
+	assert.Equal(t, "DOE", c.LastName)
+}
+
+func TestConcurrencyTrace(t *testing.T) {
+	c := Customer{FirstName: " John ", LastName: " Doe "}
+	var trace transformation.TraceNode
+	err := transformation.TransformStructWithOptions(
+		&c,
+		[]transformation.Option{transformation.Concurrency(3), transformation.WithTrace(&trace)},
+		transformation.Field(&c.FirstName, &c.FirstName, transformation.Trim),
+		transformation.Field(&c.LastName, &c.LastName, transformation.Trim),
+		transformation.Field(&c.Initials, &c.Initials, transformation.Trim),
+	)
+	if assert.NoError(t, err) {
+		var names []string
+		for _, child := range trace.Children {
+			names = append(names, child.Name)
+		}
+		assert.Equal(t, []string{"FirstName", "LastName", "Initials"}, names)
+	}
+}
@@ -0,0 +1,360 @@
+package transformation
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type (
+	// Describer is implemented by transformers which can describe themselves.
+	Describer interface {
+		// Name returns the name of the transformer, e.g. Trim.
+		Name() string
+		// Description returns a human readable description of what the transformer does.
+		Description() string
+		// Params returns the parameters the transformer was created with.
+		Params() map[string]interface{}
+	}
+
+	// ErrorDescriber is implemented by transformers which can describe when they fail.
+	ErrorDescriber interface {
+		// ErrorConditions returns human readable descriptions of the conditions the transformer
+		// fails under.
+		ErrorConditions() []string
+	}
+
+	// TransformerDescription is a serialisable description of a transformer.
+	TransformerDescription struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		Params      map[string]interface{} `json:"params,omitempty"`
+		Errors      []string               `json:"errors,omitempty"`
+		// Pipelines lists the pipelines nested in the transformer, e.g. the pipeline of Each
+		// or the branches of Try.
+		Pipelines [][]TransformerDescription `json:"pipelines,omitempty"`
+	}
+
+	// RuleDescription is a serialisable description of a TransformStruct rule.
+	RuleDescription struct {
+		// Kind is one of field, computed or split.
+		Kind     string                   `json:"kind"`
+		Field    string                   `json:"field"`
+		JSONName string                   `json:"json_name,omitempty"`
+		Sources  []string                 `json:"sources,omitempty"`
+		Targets  []string                 `json:"targets,omitempty"`
+		Pipeline []TransformerDescription `json:"pipeline,omitempty"`
+	}
+
+	// StructDescription is a serialisable description of the rules of a struct.
+	StructDescription struct {
+		Name  string            `json:"name"`
+		Rules []RuleDescription `json:"rules"`
+	}
+
+	// pipelineTransformer is implemented by the transformers which run nested pipelines.
+	pipelineTransformer interface {
+		pipelines() [][]Transformer
+	}
+)
+
+// Describe returns the description of the rules built for the struct the given pointer points to.
+func Describe(from interface{}, rules ...Rule) (*StructDescription, error) {
+	value := reflect.ValueOf(from)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must be a non-nil pointer to a struct but got %T", from)
+	}
+
+	plan, err := planRules(value.Elem(), rules)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &StructDescription{
+		Name:  structName(value),
+		Rules: make([]RuleDescription, len(rules)),
+	}
+
+	for i, rule := range rules {
+		rd := RuleDescription{
+			Kind:     ruleKind(rule),
+			Field:    plan.names[i],
+			Sources:  structFieldNames(value.Elem(), rule.sources()),
+			Targets:  structFieldNames(value.Elem(), rule.targets()),
+			Pipeline: DescribePipeline(rule.pipeline()...),
+		}
+
+		if sf := findStructField(value.Elem(), reflect.ValueOf(ruleField(rule))); sf != nil {
+			rd.JSONName, _ = jsonFieldName(*sf)
+		}
+
+		d.Rules[i] = rd
+	}
+
+	return d, nil
+}
+
+// DescribePipeline returns the descriptions of the given transformers.
+func DescribePipeline(transformers ...Transformer) []TransformerDescription {
+	if len(transformers) == 0 {
+		return nil
+	}
+
+	descriptions := make([]TransformerDescription, len(transformers))
+	for i, t := range transformers {
+		descriptions[i] = describeTransformer(t)
+	}
+
+	return descriptions
+}
+
+func describeTransformer(t Transformer) TransformerDescription {
+	d := TransformerDescription{Name: transformerName(t)}
+	if describer, ok := t.(Describer); ok {
+		d.Description = describer.Description()
+		d.Params = describer.Params()
+	}
+
+	if describer, ok := t.(ErrorDescriber); ok {
+		d.Errors = describer.ErrorConditions()
+	}
+
+	if pt, ok := t.(pipelineTransformer); ok {
+		for _, p := range pt.pipelines() {
+			d.Pipelines = append(d.Pipelines, DescribePipeline(p...))
+		}
+	}
+
+	return d
+}
+
+func ruleKind(rule Rule) string {
+	switch rule.(type) {
+	case *ComputedField:
+		return "computed"
+	case *SplitField:
+		return "split"
+	default:
+		return "field"
+	}
+}
+
+// ruleField returns the pointer to the field the rule is named after.
+func ruleField(rule Rule) interface{} {
+	if _, ok := rule.(*ComputedField); ok {
+		return rule.targets()[0]
+	}
+
+	return rule.sources()[0]
+}
+
+// structFieldNames returns the names of the struct fields the pointers point to, skipping the
+// pointers which do not point to a field of the struct.
+func structFieldNames(structValue reflect.Value, ptrs []interface{}) []string {
+	var names []string
+	for _, ptr := range ptrs {
+		pv := reflect.ValueOf(ptr)
+		if pv.Kind() != reflect.Ptr || pv.IsNil() {
+			continue
+		}
+		if sf := findStructField(structValue, pv); sf != nil {
+			names = append(names, sf.Name)
+		}
+	}
+
+	return names
+}
+
+func (t TrimTransformer) Name() string {
+	return "Trim"
+}
+
+func (t TrimTransformer) Description() string {
+	return "Removes the leading and trailing spaces, tabs and new lines."
+}
+
+func (t TrimTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t MoneyTransformer) Name() string {
+	return "Money"
+}
+
+func (t MoneyTransformer) Description() string {
+	return "Converts a float amount to an integer amount of its smallest unit by multiplying it with the division."
+}
+
+func (t MoneyTransformer) Params() map[string]interface{} {
+	return map[string]interface{}{"division": t.division}
+}
+
+func (t MoneyTransformer) ErrorConditions() []string {
+	return []string{
+		"Panics when the value is not a float32 or float64.",
+	}
+}
+
+func (t ToStringTransformer) Name() string {
+	return "ToString"
+}
+
+func (t ToStringTransformer) Description() string {
+	return "Formats the value as a string."
+}
+
+func (t ToStringTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t ReverseTransformer) Name() string {
+	return "Reverse"
+}
+
+func (t ReverseTransformer) Description() string {
+	return "Reverses the string."
+}
+
+func (t ReverseTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t ReverseTransformer) ErrorConditions() []string {
+	return []string{
+		"Panics when the value is not a string.",
+	}
+}
+
+func (t UpperCaseTransformer) Name() string {
+	return "UpperCase"
+}
+
+func (t UpperCaseTransformer) Description() string {
+	return "Converts the value to an upper case string."
+}
+
+func (t UpperCaseTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t DownCaseTransformer) Name() string {
+	return "DownCase"
+}
+
+func (t DownCaseTransformer) Description() string {
+	return "Converts the value to a lower case string."
+}
+
+func (t DownCaseTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t ParseTimeTransformer) Name() string {
+	return "ParseTime"
+}
+
+func (t ParseTimeTransformer) Description() string {
+	return "Parses the string as a time using the layout."
+}
+
+func (t ParseTimeTransformer) Params() map[string]interface{} {
+	return map[string]interface{}{"layout": t.layout}
+}
+
+func (t ParseTimeTransformer) ErrorConditions() []string {
+	return []string{
+		"Fails when the string does not match the layout.",
+	}
+}
+
+func (t inlineTransformer) Name() string {
+	if t.name != "" {
+		return t.name
+	}
+
+	return "By"
+}
+
+func (t inlineTransformer) Description() string {
+	if t.description != "" {
+		return t.description
+	}
+
+	return "Applies a custom function."
+}
+
+func (t inlineTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t eachTransformer) Name() string {
+	if t.workers > 0 {
+		return "ParallelEach"
+	}
+
+	return "Each"
+}
+
+func (t eachTransformer) Description() string {
+	return "Applies the pipeline to every element of a slice, array, map, channel or Iterator."
+}
+
+func (t eachTransformer) Params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if t.workers > 0 {
+		params["workers"] = t.workers
+	}
+	if t.batchSize > 0 {
+		params["batch_size"] = t.batchSize
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	return params
+}
+
+func (t eachTransformer) ErrorConditions() []string {
+	return []string{
+		"Fails when the value is not a slice, array, map, channel or Iterator.",
+		"Fails when the pipeline fails for any element.",
+	}
+}
+
+func (t eachTransformer) pipelines() [][]Transformer {
+	return [][]Transformer{t.transformers}
+}
+
+func (t tryTransformer) Name() string {
+	return "Try"
+}
+
+func (t tryTransformer) Description() string {
+	return "Returns the result of the first pipeline which succeeds."
+}
+
+func (t tryTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t tryTransformer) ErrorConditions() []string {
+	return []string{
+		"Fails when every pipeline fails.",
+	}
+}
+
+func (t tryTransformer) pipelines() [][]Transformer {
+	return t.branches
+}
+
+func (t defaultTransformer) Name() string {
+	return "Default"
+}
+
+func (t defaultTransformer) Description() string {
+	return "Replaces a nil or zero value with the default value."
+}
+
+func (t defaultTransformer) Params() map[string]interface{} {
+	return map[string]interface{}{"value": t.value}
+}
@@ -0,0 +1,73 @@
+package transformation_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	o := Order{}
+	d, err := transformation.Describe(
+		&o,
+		transformation.Field(&o.Customer, &o.Customer, transformation.Trim, transformation.Default("guest")),
+		transformation.Field(&o.Tags, &o.Tags, transformation.Each(transformation.Try(transformation.Money100).Or(transformation.ToString))),
+		transformation.Computed(&o.Notes, func(o *Order) (interface{}, error) {
+			return o.Customer, nil
+		}, &o.Customer),
+		transformation.Field(&o.Source, &o.Source, transformation.By(func(from interface{}) (interface{}, error) {
+			return strings.ToLower(from.(string)), nil
+		}).Named("Slug", "Turns the value into a slug.")),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err := json.Marshal(d)
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"name": "Order",
+			"rules": [
+				{
+					"kind": "field", "field": "Customer", "json_name": "customer_name",
+					"sources": ["Customer"], "targets": ["Customer"],
+					"pipeline": [
+						{"name": "Trim", "description": "Removes the leading and trailing spaces, tabs and new lines."},
+						{"name": "Default", "description": "Replaces a nil or zero value with the default value.", "params": {"value": "guest"}}
+					]
+				},
+				{
+					"kind": "field", "field": "Tags", "json_name": "tags",
+					"sources": ["Tags"], "targets": ["Tags"],
+					"pipeline": [
+						{
+							"name": "Each", "description": "Applies the pipeline to every element of a slice, array, map, channel or Iterator.",
+							"errors": ["Fails when the value is not a slice, array, map, channel or Iterator.", "Fails when the pipeline fails for any element."],
+							"pipelines": [[
+								{
+									"name": "Try", "description": "Returns the result of the first pipeline which succeeds.",
+									"errors": ["Fails when every pipeline fails."],
+									"pipelines": [
+										[{"name": "Money", "description": "Converts a float amount to an integer amount of its smallest unit by multiplying it with the division.", "params": {"division": 100}, "errors": ["Panics when the value is not a float32 or float64."]}],
+										[{"name": "ToString", "description": "Formats the value as a string."}]
+									]
+								}
+							]]
+						}
+					]
+				},
+				{
+					"kind": "computed", "field": "Notes", "json_name": "notes",
+					"sources": ["Customer"], "targets": ["Notes"]
+				},
+				{
+					"kind": "field", "field": "Source", "json_name": "source",
+					"sources": ["Source"], "targets": ["Source"],
+					"pipeline": [{"name": "Slug", "description": "Turns the value into a slug."}]
+				}
+			]
+		}`, string(b))
+	}
+}
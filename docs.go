@@ -0,0 +1,174 @@
+package transformation
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+type (
+	// fieldDoc is a row of the documentation table of a rule set.
+	fieldDoc struct {
+		Field    string
+		JSONName string
+		Steps    []string
+		Defaults []string
+		Errors   []string
+	}
+
+	// ruleSetDoc is the documentation of a rule set.
+	ruleSetDoc struct {
+		Name   string
+		Fields []fieldDoc
+	}
+)
+
+var htmlDoc = template.Must(template.New("rules").Parse(`{{range .}}<section>
+<h2>{{.Name}}</h2>
+<table>
+<thead><tr><th>Field</th><th>JSON name</th><th>Pipeline</th><th>Default</th><th>Errors</th></tr></thead>
+<tbody>
+{{- range .Fields}}
+<tr><td>{{.Field}}</td><td>{{.JSONName}}</td><td>{{if .Steps}}<ol>{{range .Steps}}<li>{{.}}</li>{{end}}</ol>{{end}}</td><td>{{range $i, $d := .Defaults}}{{if $i}}<br>{{end}}{{$d}}{{end}}</td><td>{{if .Errors}}<ul>{{range .Errors}}<li>{{.}}</li>{{end}}</ul>{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</section>
+{{end}}`))
+
+// WriteMarkdown writes a Markdown table per rule set listing every field with its JSON name,
+// the steps of its pipeline in order, its default values and the conditions it fails under.
+func WriteMarkdown(w io.Writer, sets ...*RuleSet) error {
+	var sb strings.Builder
+	for i, doc := range documentRuleSets(sets) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		fmt.Fprintf(&sb, "## %s\n\n", doc.Name)
+		sb.WriteString("| Field | JSON name | Pipeline | Default | Errors |\n")
+		sb.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, f := range doc.Fields {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n",
+				markdownCell(f.Field),
+				markdownCell(f.JSONName),
+				markdownCell(strings.Join(f.Steps, " → ")),
+				markdownCell(strings.Join(f.Defaults, ", ")),
+				markdownCell(strings.Join(f.Errors, "<br>")),
+			)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// WriteHTML writes an HTML table per rule set with the same content as WriteMarkdown.
+func WriteHTML(w io.Writer, sets ...*RuleSet) error {
+	return htmlDoc.Execute(w, documentRuleSets(sets))
+}
+
+func documentRuleSets(sets []*RuleSet) []ruleSetDoc {
+	docs := make([]ruleSetDoc, len(sets))
+	for i, rs := range sets {
+		docs[i] = ruleSetDoc{Name: rs.Name, Fields: make([]fieldDoc, len(rs.Fields))}
+		for j, field := range rs.Fields {
+			docs[i].Fields[j] = documentField(field)
+		}
+	}
+
+	return docs
+}
+
+func documentField(field FieldRules) fieldDoc {
+	doc := fieldDoc{
+		Field:    field.Field,
+		JSONName: field.JSONName,
+	}
+
+	pipeline := DescribePipeline(field.transformers...)
+	for _, d := range pipeline {
+		doc.Steps = append(doc.Steps, formatStep(d))
+		if d.Name == "Default" {
+			doc.Defaults = append(doc.Defaults, formatParam(d.Params["value"]))
+		}
+	}
+
+	seen := map[string]bool{}
+	collectErrors(pipeline, seen, &doc.Errors)
+
+	return doc
+}
+
+// formatStep formats the description of a transformer, e.g. Money(division=100) or
+// Each(Trim → UpperCase).
+func formatStep(d TransformerDescription) string {
+	var args []string
+
+	keys := make([]string, 0, len(d.Params))
+	for k := range d.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k+"="+formatParam(d.Params[k]))
+	}
+
+	for _, p := range d.Pipelines {
+		steps := make([]string, len(p))
+		for i, step := range p {
+			steps[i] = formatStep(step)
+		}
+		args = append(args, strings.Join(steps, " → "))
+	}
+
+	if len(args) == 0 {
+		return d.Name
+	}
+
+	sep := ", "
+	if d.Name == "Try" {
+		sep = " | "
+	}
+
+	return d.Name + "(" + strings.Join(args, sep) + ")"
+}
+
+func formatParam(v interface{}) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// collectErrors appends the error conditions of the pipeline and its nested pipelines, skipping
+// the ones already seen.
+func collectErrors(pipeline []TransformerDescription, seen map[string]bool, errs *[]string) {
+	for _, d := range pipeline {
+		for _, e := range d.Errors {
+			e = d.Name + ": " + e
+			if !seen[e] {
+				seen[e] = true
+				*errs = append(*errs, e)
+			}
+		}
+
+		for _, p := range d.Pipelines {
+			collectErrors(p, seen, errs)
+		}
+	}
+}
+
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+
+	return strings.Replace(s, "\n", " ", -1)
+}
@@ -0,0 +1,58 @@
+package transformation_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	rs, err := transformation.NewRuleSet("Signup",
+		transformation.FieldRules{Field: "Phone", JSONName: "phone", Pipeline: `trim|default("unknown")`},
+		transformation.FieldRules{Field: "Tags", JSONName: "tags", Pipeline: "each(trim|try(money(100), to_string))"},
+		transformation.FieldRules{Field: "Name", Pipeline: "upper_case"},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	if assert.NoError(t, transformation.WriteMarkdown(&buf, rs)) {
+		assert.Equal(t, "## Signup\n\n"+
+			"| Field | JSON name | Pipeline | Default | Errors |\n"+
+			"| --- | --- | --- | --- | --- |\n"+
+			"| Phone | phone | Trim → Default(value=\"unknown\") | \"unknown\" |  |\n"+
+			"| Tags | tags | Each(Trim → Try(Money(division=100) \\| ToString)) |  | "+
+			"Each: Fails when the value is not a slice, array, map, channel or Iterator.<br>"+
+			"Each: Fails when the pipeline fails for any element.<br>"+
+			"Try: Fails when every pipeline fails.<br>"+
+			"Money: Panics when the value is not a float32 or float64. |\n"+
+			"| Name |  | UpperCase |  |  |\n", buf.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	rs, err := transformation.NewRuleSet("Signup",
+		transformation.FieldRules{Field: "Phone", JSONName: "phone", Pipeline: `default("<none>")`},
+		transformation.FieldRules{Field: "Born", JSONName: "born", Pipeline: `parse_time("2006-01-02")`},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	if assert.NoError(t, transformation.WriteHTML(&buf, rs)) {
+		assert.Equal(t, `<section>
+<h2>Signup</h2>
+<table>
+<thead><tr><th>Field</th><th>JSON name</th><th>Pipeline</th><th>Default</th><th>Errors</th></tr></thead>
+<tbody>
+<tr><td>Phone</td><td>phone</td><td><ol><li>Default(value=&#34;&lt;none&gt;&#34;)</li></ol></td><td>&#34;&lt;none&gt;&#34;</td><td></td></tr>
+<tr><td>Born</td><td>born</td><td><ol><li>ParseTime(layout=&#34;2006-01-02&#34;)</li></ol></td><td></td><td><ul><li>ParseTime: Fails when the string does not match the layout.</li></ul></td></tr>
+</tbody>
+</table>
+</section>
+`, buf.String())
+	}
+}
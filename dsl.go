@@ -0,0 +1,473 @@
+package transformation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// TransformerFactory creates a transformer from the arguments given in the pipeline DSL.
+	// The arguments are passed as written, so quoted ones can be told apart; use UnquoteArg
+	// to get their value.
+	TransformerFactory func(args ...string) (Transformer, error)
+
+	// pipelineStep is a single step of a pipeline written in the pipeline DSL.
+	pipelineStep struct {
+		name string
+		args []string
+	}
+)
+
+var registry struct {
+	sync.RWMutex
+	factories map[string]TransformerFactory
+}
+
+func init() {
+	registry.factories = map[string]TransformerFactory{
+		"trim":          noArgs(Trim),
+		"upper_case":    noArgs(UpperCase),
+		"down_case":     noArgs(DownCase),
+		"to_string":     noArgs(ToString),
+		"reverse":       noArgs(Reverse),
+		"money":         newMoney,
+		"parse_time":    newParseTime,
+		"default":       newDefault,
+		"each":          newEach,
+		"parallel_each": newParallelEach,
+		"try":           newTry,
+		"unique":        noArgs(Unique()),
+		"sort":          noArgs(Sort()),
+		"reverse_order": noArgs(ReverseOrder()),
+		"flatten":       noArgs(Flatten()),
+		"chunk":         newChunk,
+		"take":          newTake,
+		"skip":          newSkip,
+		"split":         newSplitString,
+		"split_regexp":  newSplitRegexp,
+		"split_csv":     newSplitCSV,
+		"join":          newJoin,
+		"join_csv":      newJoinCSV,
+		"index_by":      newIndexBy,
+		"group_by":      newGroupBy,
+	}
+}
+
+// RegisterTransformer makes a transformer available in the pipeline DSL under the given name,
+// replacing any transformer registered with the same name.
+func RegisterTransformer(name string, factory TransformerFactory) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.factories[name] = factory
+}
+
+// RegisteredTransformers returns the sorted names of the transformers available in the pipeline DSL.
+func RegisteredTransformers() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ParsePipeline parses a pipeline written in the pipeline DSL into registered transformers.
+// Steps are separated by | and arguments are given in parentheses, separated by commas, e.g.
+// trim|default("n/a")|each(upper_case). Arguments containing separators must be quoted.
+func ParsePipeline(src string) ([]Transformer, error) {
+	steps, err := parsePipelineSteps(src)
+	if err != nil {
+		return nil, err
+	}
+
+	transformers := make([]Transformer, len(steps))
+	for i, step := range steps {
+		registry.RLock()
+		factory, ok := registry.factories[step.name]
+		registry.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown transformer %q", step.name)
+		}
+
+		if transformers[i], err = factory(step.args...); err != nil {
+			return nil, fmt.Errorf("%s: %v", step.name, err)
+		}
+	}
+
+	return transformers, nil
+}
+
+func parsePipelineSteps(src string) ([]pipelineStep, error) {
+	parts, err := splitTopLevel(src, '|')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parts) == 1 && strings.TrimSpace(parts[0]) == "" {
+		return nil, nil
+	}
+
+	steps := make([]pipelineStep, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty step in pipeline %q", src)
+		}
+
+		open := strings.IndexByte(part, '(')
+		if open < 0 {
+			steps[i] = pipelineStep{name: part}
+			continue
+		}
+
+		if closing := closingParen(part, open); closing < 0 {
+			return nil, fmt.Errorf("missing closing parenthesis in %q", part)
+		} else if closing != len(part)-1 {
+			return nil, fmt.Errorf("unexpected %q after the arguments in %q", part[closing+1:], part)
+		}
+
+		steps[i].name = strings.TrimSpace(part[:open])
+		args, err := splitTopLevel(part[open+1:len(part)-1], ',')
+		if err != nil {
+			return nil, err
+		}
+
+		if len(args) == 1 && strings.TrimSpace(args[0]) == "" {
+			args = nil
+		}
+
+		for _, arg := range args {
+			steps[i].args = append(steps[i].args, strings.TrimSpace(arg))
+		}
+	}
+
+	return steps, nil
+}
+
+// splitTopLevel splits the string by the separator, ignoring the separators nested in
+// parentheses or quotes.
+func splitTopLevel(s string, sep byte) ([]string, error) {
+	var parts []string
+	depth := 0
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected closing parenthesis in %q", s)
+			}
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("missing closing parenthesis in %q", s)
+	}
+
+	return append(parts, s[start:]), nil
+}
+
+// closingParen returns the index of the parenthesis closing the one at the given index, or -1
+// if it is not closed.
+func closingParen(s string, open int) int {
+	depth := 0
+	quoted := false
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// UnquoteArg returns the value of a pipeline DSL argument, removing its quotes if it is quoted.
+func UnquoteArg(arg string) string {
+	if len(arg) >= 2 && arg[0] == '"' {
+		if s, err := strconv.Unquote(arg); err == nil {
+			return s
+		}
+	}
+
+	return arg
+}
+
+func noArgs(t Transformer) TransformerFactory {
+	return func(args ...string) (Transformer, error) {
+		if len(args) > 0 {
+			return nil, errors.New("expects no arguments")
+		}
+
+		return t, nil
+	}
+}
+
+func newMoney(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the division")
+	}
+
+	division, err := strconv.Atoi(args[0])
+	if err != nil || division <= 0 {
+		return nil, fmt.Errorf("invalid division %q", args[0])
+	}
+
+	return Money(division), nil
+}
+
+func newParseTime(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the layout")
+	}
+
+	return ParseTime(UnquoteArg(args[0])), nil
+}
+
+// newDefault creates a Default transformer. Unquoted integers are used as int, other numbers
+// as float64 and booleans as such; anything else is used as a string.
+func newDefault(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the default value")
+	}
+
+	if args[0] != UnquoteArg(args[0]) {
+		return Default(UnquoteArg(args[0])), nil
+	}
+
+	if i, err := strconv.Atoi(args[0]); err == nil {
+		return Default(i), nil
+	}
+
+	if f, err := strconv.ParseFloat(args[0], 64); err == nil {
+		return Default(f), nil
+	}
+
+	if b, err := strconv.ParseBool(args[0]); err == nil {
+		return Default(b), nil
+	}
+
+	return Default(args[0]), nil
+}
+
+func newEach(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects a pipeline")
+	}
+
+	transformers, err := ParsePipeline(UnquoteArg(args[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	return Each(transformers...), nil
+}
+
+func newParallelEach(args ...string) (Transformer, error) {
+	if len(args) != 2 {
+		return nil, errors.New("expects the number of workers and a pipeline")
+	}
+
+	workers, err := strconv.Atoi(args[0])
+	if err != nil || workers <= 0 {
+		return nil, fmt.Errorf("invalid number of workers %q", args[0])
+	}
+
+	transformers, err := ParsePipeline(UnquoteArg(args[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParallelEach(workers, transformers...), nil
+}
+
+func newChunk(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the size")
+	}
+
+	size, err := strconv.Atoi(args[0])
+	if err != nil || size <= 0 {
+		return nil, fmt.Errorf("invalid size %q", args[0])
+	}
+
+	return Chunk(size), nil
+}
+
+func newTake(args ...string) (Transformer, error) {
+	n, err := countArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return Take(n), nil
+}
+
+func newSkip(args ...string) (Transformer, error) {
+	n, err := countArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return Skip(n), nil
+}
+
+// countArg returns the number of elements given as the only argument.
+func countArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expects the number of elements")
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number of elements %q", args[0])
+	}
+
+	return n, nil
+}
+
+func newSplitString(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the separator")
+	}
+
+	return SplitString(UnquoteArg(args[0])), nil
+}
+
+func newSplitRegexp(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the pattern")
+	}
+
+	re, err := regexp.Compile(UnquoteArg(args[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitRegexp(re), nil
+}
+
+func newSplitCSV(args ...string) (Transformer, error) {
+	sep, err := separatorArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitCSV(sep), nil
+}
+
+func newJoin(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the separator")
+	}
+
+	return Join(UnquoteArg(args[0])), nil
+}
+
+func newJoinCSV(args ...string) (Transformer, error) {
+	sep, err := separatorArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return JoinCSV(sep), nil
+}
+
+// newIndexBy creates an IndexBy transformer keyed by the field given as the first argument.
+// It is strict if the second argument is strict.
+func newIndexBy(args ...string) (Transformer, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, errors.New("expects the field and optionally strict")
+	}
+
+	t := IndexBy(FieldKey(UnquoteArg(args[0])))
+	if len(args) == 1 {
+		return t, nil
+	}
+
+	if UnquoteArg(args[1]) != "strict" {
+		return nil, fmt.Errorf("unexpected argument %q", args[1])
+	}
+
+	return t.Strict(), nil
+}
+
+func newGroupBy(args ...string) (Transformer, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expects the field")
+	}
+
+	return GroupBy(FieldKey(UnquoteArg(args[0]))), nil
+}
+
+// separatorArg returns the single character separator given as the only argument, or the
+// comma if there is none.
+func separatorArg(args []string) (rune, error) {
+	if len(args) == 0 {
+		return ',', nil
+	}
+
+	sep := []rune(UnquoteArg(args[0]))
+	if len(args) > 1 || len(sep) != 1 {
+		return 0, errors.New("expects a single character separator")
+	}
+
+	return sep[0], nil
+}
+
+func newTry(args ...string) (Transformer, error) {
+	if len(args) == 0 {
+		return nil, errors.New("expects at least one pipeline")
+	}
+
+	var t *tryTransformer
+	for _, arg := range args {
+		transformers, err := ParsePipeline(UnquoteArg(arg))
+		if err != nil {
+			return nil, err
+		}
+
+		if t == nil {
+			t = Try(transformers...)
+			continue
+		}
+		t = t.Or(transformers...)
+	}
+
+	return t, nil
+}
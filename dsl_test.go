@@ -0,0 +1,112 @@
+package transformation_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strings"
+	"testing"
+)
+
+func TestParsePipeline(t *testing.T) {
+	ts, err := transformation.ParsePipeline(`trim | default("n/a") | each("trim|upper_case") | money(100)`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []transformation.TransformerDescription{
+			{Name: "Trim"},
+			{Name: "Default", Params: map[string]interface{}{"value": "n/a"}},
+			{Name: "Each", Pipelines: [][]transformation.TransformerDescription{{{Name: "Trim"}, {Name: "UpperCase"}}}},
+			{Name: "Money", Params: map[string]interface{}{"division": 100}},
+		}, stripDescriptions(transformation.DescribePipeline(ts...)))
+	}
+
+	ts, err = transformation.ParsePipeline(`default(42)|try(money(100), to_string)|parse_time("2006-01-02")`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []transformation.TransformerDescription{
+			{Name: "Default", Params: map[string]interface{}{"value": 42}},
+			{Name: "Try", Pipelines: [][]transformation.TransformerDescription{{{Name: "Money", Params: map[string]interface{}{"division": 100}}}, {{Name: "ToString"}}}},
+			{Name: "ParseTime", Params: map[string]interface{}{"layout": "2006-01-02"}},
+		}, stripDescriptions(transformation.DescribePipeline(ts...)))
+	}
+
+	ts, err = transformation.ParsePipeline("")
+	if assert.NoError(t, err) {
+		assert.Empty(t, ts)
+	}
+}
+
+func TestParsePipelineDefaultNumber(t *testing.T) {
+	ts, err := transformation.ParsePipeline("default(7)")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var n int
+	if assert.NoError(t, transformation.Transform((*int)(nil), &n, ts...)) {
+		assert.Equal(t, 7, n)
+	}
+}
+
+func TestParsePipelineErrors(t *testing.T) {
+	tests := map[string]string{
+		"trim|":              `empty step in pipeline "trim|"`,
+		"trim|slugify":       `unknown transformer "slugify"`,
+		"trim(1)":            "trim: expects no arguments",
+		"money(abc)":         `money: invalid division "abc"`,
+		`default("n/a`:       `unterminated quote in "default(\"n/a"`,
+		"each(trim":          `missing closing parenthesis in "each(trim"`,
+		"each(trim|nope)":    `each: unknown transformer "nope"`,
+		"parse_time()":       "parse_time: expects the layout",
+		"trim)":              `unexpected closing parenthesis in "trim)"`,
+		"try()":              "try: expects at least one pipeline",
+		"money(100)(200)":    `unexpected "(200)" after the arguments in "money(100)(200)"`,
+		"default(1, 2)":      "default: expects the default value",
+		"upper_case|money()": "money: expects the division",
+	}
+
+	for src, msg := range tests {
+		_, err := transformation.ParsePipeline(src)
+		if assert.Error(t, err, src) {
+			assert.Equal(t, msg, err.Error(), src)
+		}
+	}
+}
+
+func TestRegisterTransformer(t *testing.T) {
+	transformation.RegisterTransformer("slugify", func(args ...string) (transformation.Transformer, error) {
+		if len(args) > 0 {
+			return nil, errors.New("expects no arguments")
+		}
+
+		return transformation.By(func(from interface{}) (interface{}, error) {
+			return strings.Replace(strings.ToLower(from.(string)), " ", "-", -1), nil
+		}).Named("Slugify", "Turns the value into a slug."), nil
+	})
+	t.Cleanup(func() {
+		transformation.UnregisterTransformer("slugify")
+	})
+
+	assert.Contains(t, transformation.RegisteredTransformers(), "slugify")
+
+	ts, err := transformation.ParsePipeline("trim|slugify")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var to string
+	err = transformation.Transform(" Hello World ", &to, ts...)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hello-world", to)
+	}
+}
+
+func stripDescriptions(ds []transformation.TransformerDescription) []transformation.TransformerDescription {
+	for i := range ds {
+		ds[i].Description = ""
+		ds[i].Errors = nil
+		for _, p := range ds[i].Pipelines {
+			stripDescriptions(p)
+		}
+	}
+
+	return ds
+}
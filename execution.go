@@ -0,0 +1,196 @@
+package transformation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type (
+	// execution carries the state of a transformation through the pipelines it runs,
+	// including the pipelines nested in transformers such as Each.
+	execution struct {
+		ctx          context.Context
+		path         string
+		interceptors []Interceptor
+		trace        *TraceNode
+		metrics      Metrics
+	}
+
+	// executionTransformer is implemented by the transformers which run pipelines of their own,
+	// so the execution is propagated to the nested pipelines.
+	executionTransformer interface {
+		transformIn(e *execution, from interface{}) (interface{}, error)
+	}
+)
+
+// newExecution returns an execution using the global interceptors followed by the given ones.
+func newExecution(ctx context.Context, interceptors []Interceptor) *execution {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &execution{
+		ctx:          ctx,
+		interceptors: append(globalInterceptorList(), interceptors...),
+		metrics:      globalMetricsValue(),
+	}
+}
+
+// field returns a copy of the execution for the struct field with the given name.
+func (e *execution) field(name string) *execution {
+	c := *e
+	c.path = name
+
+	return &c
+}
+
+// child returns a copy of the execution for an element of the current value, e.g. a slice
+// index or a map key.
+func (e *execution) child(key string) *execution {
+	c := *e
+	if c.path == "" {
+		c.path = key
+	} else {
+		c.path += "." + key
+	}
+
+	return &c
+}
+
+func (e *execution) withContext(ctx context.Context) *execution {
+	if ctx == e.ctx {
+		return e
+	}
+
+	c := *e
+	c.ctx = ctx
+
+	return &c
+}
+
+// transformTo transforms the value and copies the result to the given pointer.
+func (e *execution) transformTo(from interface{}, to interface{}, transformers []Transformer) error {
+	v := reflect.ValueOf(to)
+	if v.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("%T must be a pointer", to))
+	}
+
+	if !v.Elem().CanSet() {
+		panic(fmt.Errorf("%T must be a addressable", to))
+	}
+
+	tmpTo, err := e.transform(from, transformers)
+	if err != nil {
+		return err
+	}
+
+	if tmpTo == nil {
+		return nil
+	}
+
+	mustCopyValue(tmpTo, to)
+
+	return nil
+}
+
+// observe reports a whole transformation started at the given time to the metrics.
+func (e *execution) observe(start time.Time, err error) {
+	if e.metrics != nil {
+		e.metrics.ObserveTransformation(time.Since(start), err)
+	}
+}
+
+// transform transforms a Transformable value and applies the transformers to the result.
+func (e *execution) transform(from interface{}, transformers []Transformer) (interface{}, error) {
+	tmpTo := from
+	if v, ok := from.(Transformable); ok {
+		var err error
+		if tmpTo, err = v.Transform(); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.apply(tmpTo, transformers)
+}
+
+// apply runs the transformers one after another, each receiving the result of the previous one.
+func (e *execution) apply(from interface{}, transformers []Transformer) (interface{}, error) {
+	return e.applyFrom(from, transformers, 0)
+}
+
+// applyFrom is like apply for the transformers of a pipeline starting at the given index.
+func (e *execution) applyFrom(from interface{}, transformers []Transformer, first int) (interface{}, error) {
+	from, _ = indirect(from)
+
+	if len(transformers) == 0 {
+		return from, nil
+	}
+
+	var to interface{}
+	var err error
+	for i, transformer := range transformers {
+		to, err = e.invoke(first+i, transformer, from)
+		if err != nil {
+			return nil, err
+		}
+		from = to
+	}
+
+	return to, nil
+}
+
+// invoke calls the transformer through the interceptors of the execution.
+func (e *execution) invoke(index int, t Transformer, from interface{}) (interface{}, error) {
+	return e.intercept(index, t, from, func(se *execution, in interface{}) (interface{}, error) {
+		if et, ok := t.(executionTransformer); ok {
+			return et.transformIn(se, in)
+		}
+
+		return t.Transform(in)
+	})
+}
+
+// intercept calls fn with the value through the interceptors of the execution, tracing and
+// observing the call as a step of the transformer.
+func (e *execution) intercept(index int, t Transformer, from interface{}, fn func(se *execution, in interface{}) (interface{}, error)) (interface{}, error) {
+	next := func(ctx context.Context, in interface{}) (interface{}, error) {
+		se, node := e.withContext(ctx).startTrace(traceStep, transformerName(t), in)
+		start := time.Now()
+
+		to, err := fn(se, in)
+		node.finish(to, err)
+
+		if e.metrics != nil {
+			e.metrics.ObserveStep(e.stepInfo(index, t), time.Since(start), err)
+		}
+
+		return to, err
+	}
+
+	if len(e.interceptors) == 0 {
+		return next(e.ctx, from)
+	}
+
+	info := e.stepInfo(index, t)
+
+	for i := len(e.interceptors) - 1; i >= 0; i-- {
+		interceptor := e.interceptors[i]
+		inner := next
+		next = func(ctx context.Context, in interface{}) (interface{}, error) {
+			return interceptor(ctx, info, in, inner)
+		}
+	}
+
+	return next(e.ctx, from)
+}
+
+func (e *execution) stepInfo(index int, t Transformer) StepInfo {
+	return StepInfo{
+		Field:       e.path,
+		Name:        transformerName(t),
+		Index:       index,
+		Transformer: t,
+	}
+}
@@ -1,5 +1,7 @@
 package transformation
 
+import "time"
+
 var (
 	ApplyTransformers   = applyTransformers
 	CopyValue           = copyValue
@@ -12,4 +14,16 @@ var (
 	MapHasSameValueType = mapHasSameValueType
 	MapHasSameKeyType   = mapHasSameKeyType
 	ToConcreteMap       = toConcreteMap
+	DeepCopy            = deepCopy
 )
+
+func SetCacheClock(t *CachedTransformer, now func() time.Time) {
+	t.now = now
+}
+
+func UnregisterTransformer(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	delete(registry.factories, name)
+}
@@ -0,0 +1,229 @@
+package transformation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+type (
+	// indexTransformer turns a collection into a map of its elements keyed by KeyFunc.
+	indexTransformer struct {
+		key    KeyFunc
+		strict bool
+	}
+
+	// groupTransformer turns a collection into a map of slices of its elements keyed by KeyFunc.
+	groupTransformer struct {
+		key KeyFunc
+	}
+)
+
+// FieldKey returns a KeyFunc which returns the value of the exported struct field, or of the
+// map entry, with the given name, e.g. IndexBy(FieldKey("ID")).
+func FieldKey(name string) KeyFunc {
+	return func(v interface{}) (interface{}, error) {
+		v, isNil := indirect(v)
+		if isNil {
+			return nil, fmt.Errorf("no %s in nil", name)
+		}
+
+		value := reflect.ValueOf(v)
+		switch value.Kind() {
+		case reflect.Struct:
+			sf, ok := value.Type().FieldByName(name)
+			if !ok || sf.PkgPath != "" {
+				return nil, fmt.Errorf("no field %s in %T", name, v)
+			}
+
+			f, ok := fieldByIndex(value, sf.Index)
+			if !ok {
+				return nil, fmt.Errorf("no field %s in %T", name, v)
+			}
+
+			return f.Interface(), nil
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("no %s in %T", name, v)
+			}
+
+			entry := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !entry.IsValid() {
+				return nil, fmt.Errorf("no %s in %T", name, v)
+			}
+
+			return entry.Interface(), nil
+		default:
+			return nil, fmt.Errorf("must be a struct or a map but got %T", v)
+		}
+	}
+}
+
+// IndexBy returns a transformer which turns the elements of a slice, an array or the values of
+// a map into a map keyed by the given function, e.g. []Order into map[OrderID]Order. The map is
+// keyed by the type of the keys if they all have the same one. Of the elements with the same
+// key, the last one is kept, unless the transformer is Strict.
+func IndexBy(key KeyFunc) *indexTransformer {
+	return &indexTransformer{key: key}
+}
+
+// Strict returns a copy of the transformer which fails for the elements with the same key as
+// an element before them. The failures are returned as Errors keyed by element position.
+func (t *indexTransformer) Strict() *indexTransformer {
+	return &indexTransformer{key: t.key, strict: true}
+}
+
+// GroupBy returns a transformer which groups the elements of a slice, an array or the values
+// of a map by the key returned by the given function, e.g. []Order into
+// map[CustomerID][]Order. The elements of a group keep their order.
+func GroupBy(key KeyFunc) *groupTransformer {
+	return &groupTransformer{key: key}
+}
+
+func (t *indexTransformer) Transform(from interface{}) (interface{}, error) {
+	c, keys, err := keyedCollection(from, t.key)
+	if c == nil || err != nil {
+		return nil, err
+	}
+
+	m := reflect.MakeMapWithSize(reflect.MapOf(commonType(keys), c.sliceType.Elem()), len(keys))
+	seen := make(map[interface{}]int, len(keys))
+	errs := Errors{}
+	for i, k := range keys {
+		if first, ok := seen[k]; ok && t.strict {
+			errs[strconv.Itoa(i)] = fmt.Errorf("duplicate key %v of element %d", k, first)
+			continue
+		}
+
+		seen[k] = i
+		m.SetMapIndex(reflect.ValueOf(k), c.elements[i])
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return m.Interface(), nil
+}
+
+func (t *indexTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	_, err := collectionSliceType(in)
+
+	return nil, err
+}
+
+func (t *indexTransformer) Name() string {
+	return "IndexBy"
+}
+
+func (t *indexTransformer) Description() string {
+	return "Turns the elements into a map keyed by a key of every element."
+}
+
+func (t *indexTransformer) Params() map[string]interface{} {
+	if !t.strict {
+		return nil
+	}
+
+	return map[string]interface{}{"strict": true}
+}
+
+func (t *indexTransformer) ErrorConditions() []string {
+	conditions := []string{
+		"Fails when the value is not a slice, array or map.",
+		"Fails when the key of an element is nil, not comparable or cannot be read.",
+	}
+	if t.strict {
+		conditions = append(conditions, "Fails when several elements have the same key.")
+	}
+
+	return conditions
+}
+
+func (t *groupTransformer) Transform(from interface{}) (interface{}, error) {
+	c, keys, err := keyedCollection(from, t.key)
+	if c == nil || err != nil {
+		return nil, err
+	}
+
+	m := reflect.MakeMap(reflect.MapOf(commonType(keys), c.sliceType))
+	for i, k := range keys {
+		kv := reflect.ValueOf(k)
+		group := m.MapIndex(kv)
+		if !group.IsValid() {
+			group = reflect.MakeSlice(c.sliceType, 0, 1)
+		}
+
+		m.SetMapIndex(kv, reflect.Append(group, c.elements[i]))
+	}
+
+	return m.Interface(), nil
+}
+
+func (t *groupTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	_, err := collectionSliceType(in)
+
+	return nil, err
+}
+
+func (t *groupTransformer) Name() string {
+	return "GroupBy"
+}
+
+func (t *groupTransformer) Description() string {
+	return "Groups the elements into a map of slices keyed by a key of every element."
+}
+
+func (t *groupTransformer) Params() map[string]interface{} {
+	return nil
+}
+
+func (t *groupTransformer) ErrorConditions() []string {
+	return []string{
+		"Fails when the value is not a slice, array or map.",
+		"Fails when the key of an element is nil, not comparable or cannot be read.",
+	}
+}
+
+// keyedCollection returns the collection the value references along with the key of every
+// element. The failures to get a key are returned as Errors keyed by element position.
+func keyedCollection(from interface{}, key KeyFunc) (*collection, []interface{}, error) {
+	c, err := newCollection(from)
+	if c == nil || err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]interface{}, len(c.values))
+	errs := Errors{}
+	for i, v := range c.values {
+		k, err := key(v)
+		switch {
+		case err != nil:
+		case k == nil:
+			err = errors.New("nil key")
+		case !isHashable(k):
+			err = fmt.Errorf("%T key is not comparable", k)
+		}
+
+		if err != nil {
+			errs[strconv.Itoa(i)] = err
+			continue
+		}
+		keys[i] = k
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	return c, keys, nil
+}
@@ -0,0 +1,119 @@
+package transformation_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"reflect"
+	"testing"
+)
+
+type (
+	PurchaseID int
+	CustomerID string
+
+	Purchase struct {
+		ID       PurchaseID
+		Customer CustomerID
+		Total    float64
+	}
+
+	Purchases []Purchase
+)
+
+var purchases = Purchases{
+	{ID: 1, Customer: "ann", Total: 10},
+	{ID: 2, Customer: "bob", Total: 20},
+	{ID: 3, Customer: "ann", Total: 30},
+}
+
+func TestIndexBy(t *testing.T) {
+	out, err := transformation.IndexBy(transformation.FieldKey("ID")).Transform(purchases)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[PurchaseID]Purchase{1: purchases[0], 2: purchases[1], 3: purchases[2]}, out)
+	}
+
+	byCustomer := transformation.IndexBy(transformation.FieldKey("Customer"))
+	out, err = byCustomer.Transform(purchases)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[CustomerID]Purchase{"ann": purchases[2], "bob": purchases[1]}, out)
+	}
+
+	_, err = byCustomer.Strict().Transform(purchases)
+	assert.Equal(t, transformation.Errors{"2": errors.New("duplicate key ann of element 0")}, err)
+
+	out, err = transformation.IndexBy(transformation.FieldKey("id")).Transform([]map[string]interface{}{
+		{"id": "a", "n": 1},
+		{"id": 2, "n": 2},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[interface{}]map[string]interface{}{
+			"a": {"id": "a", "n": 1},
+			2:   {"id": 2, "n": 2},
+		}, out)
+	}
+
+	_, err = transformation.IndexBy(transformation.FieldKey("Missing")).Transform([]*Purchase{&purchases[0], nil})
+	assert.Equal(t, transformation.Errors{
+		"0": errors.New("no field Missing in transformation_test.Purchase"),
+		"1": errors.New("no Missing in nil"),
+	}, err)
+
+	_, err = transformation.IndexBy(func(v interface{}) (interface{}, error) {
+		return []int{}, nil
+	}).Transform([]int{1})
+	assert.Equal(t, transformation.Errors{"0": errors.New("[]int key is not comparable")}, err)
+
+	_, err = transformation.IndexBy(func(v interface{}) (interface{}, error) {
+		return struct{ V interface{} }{V: v}, nil
+	}).Transform([]interface{}{1, []int{2}})
+	assert.Equal(t, transformation.Errors{"1": errors.New("struct { V interface {} } key is not comparable")}, err)
+}
+
+func TestGroupBy(t *testing.T) {
+	out, err := transformation.GroupBy(transformation.FieldKey("Customer")).Transform(purchases)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[CustomerID]Purchases{
+			"ann": {purchases[0], purchases[2]},
+			"bob": {purchases[1]},
+		}, out)
+	}
+
+	out, err = transformation.GroupBy(func(v interface{}) (interface{}, error) {
+		return len(v.(string)), nil
+	}).Transform(map[int]string{1: "a", 2: "bc", 3: "d"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[int][]string{1: {"a", "d"}, 2: {"bc"}}, out)
+	}
+
+	_, err = transformation.GroupBy(transformation.FieldKey("Customer")).Transform("purchases")
+	assert.EqualError(t, err, "must be a slice, array or map but got string")
+}
+
+func TestIndexByPipeline(t *testing.T) {
+	ts, err := transformation.ParsePipeline(`index_by("ID", strict)`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var byID map[PurchaseID]Purchase
+	if assert.NoError(t, transformation.Transform(purchases, &byID, ts...)) {
+		assert.Len(t, byID, 3)
+	}
+
+	ts, err = transformation.ParsePipeline(`group_by("Customer")`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var byCustomer map[CustomerID][]Purchase
+	if assert.NoError(t, transformation.Transform(purchases, &byCustomer, ts...)) {
+		assert.Equal(t, []Purchase{purchases[0], purchases[2]}, byCustomer["ann"])
+	}
+
+	_, err = transformation.ParsePipeline(`index_by("ID", loose)`)
+	assert.EqualError(t, err, `index_by: unexpected argument "loose"`)
+
+	_, err = transformation.Check(reflect.TypeOf(0), ts...)
+	assert.EqualError(t, err, "step 1 (GroupBy): expected a slice, array or map but got int")
+}
@@ -0,0 +1,90 @@
+package transformation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type (
+	// StepInfo describes a single transformer invocation.
+	StepInfo struct {
+		// Field is the path of the value being transformed, e.g. Addresses.1 for the second
+		// element of the Addresses field. It is empty outside of TransformStruct.
+		Field string
+		// Name is the name of the transformer.
+		Name string
+		// Index is the position of the transformer in its pipeline.
+		Index       int
+		Transformer Transformer
+	}
+
+	// Invoker calls the next interceptor or, at the end of the chain, the transformer itself.
+	Invoker func(ctx context.Context, in interface{}) (interface{}, error)
+
+	// Interceptor is called around every transformer invocation. It must call next to continue
+	// the invocation, usually with the given context and input.
+	Interceptor func(ctx context.Context, info StepInfo, in interface{}, next Invoker) (interface{}, error)
+)
+
+var globalInterceptors struct {
+	sync.RWMutex
+	list []Interceptor
+}
+
+// UseInterceptors installs interceptors used by every transformation. They are called before
+// the interceptors given with WithInterceptors, in the order they were installed.
+func UseInterceptors(interceptors ...Interceptor) {
+	globalInterceptors.Lock()
+	defer globalInterceptors.Unlock()
+
+	globalInterceptors.list = append(globalInterceptors.list, interceptors...)
+}
+
+// ResetInterceptors removes every interceptor installed with UseInterceptors.
+func ResetInterceptors() {
+	globalInterceptors.Lock()
+	defer globalInterceptors.Unlock()
+
+	globalInterceptors.list = nil
+}
+
+// WithInterceptors adds interceptors used by a single TransformStructWithOptions call.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithContext sets the context passed to the interceptors.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// RecoverPanics is an interceptor which turns a panicking transformer into an error.
+func RecoverPanics(ctx context.Context, info StepInfo, in interface{}, next Invoker) (to interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			to = nil
+			err = fmt.Errorf("%s panicked: %v", info.Name, r)
+		}
+	}()
+
+	return next(ctx, in)
+}
+
+func globalInterceptorList() []Interceptor {
+	globalInterceptors.RLock()
+	defer globalInterceptors.RUnlock()
+
+	if len(globalInterceptors.list) == 0 {
+		return nil
+	}
+
+	list := make([]Interceptor, len(globalInterceptors.list))
+	copy(list, globalInterceptors.list)
+
+	return list
+}
@@ -0,0 +1,87 @@
+package transformation_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	var calls []string
+	record := func(prefix string) transformation.Interceptor {
+		return func(ctx context.Context, info transformation.StepInfo, in interface{}, next transformation.Invoker) (interface{}, error) {
+			out, err := next(ctx, in)
+			calls = append(calls, fmt.Sprintf("%s %s %s#%d %q->%q", prefix, info.Field, info.Name, info.Index, in, out))
+
+			return out, err
+		}
+	}
+
+	transformation.UseInterceptors(record("global"))
+	defer transformation.ResetInterceptors()
+
+	from := Person{FirstName: " John ", Addresses: []string{" a"}}
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{transformation.WithInterceptors(record("call"))},
+		transformation.Field(&from.FirstName, &from.FirstName, transformation.Trim, transformation.UpperCase),
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.Each(transformation.Trim)),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			`call FirstName Trim#0 " John "->"John"`,
+			`global FirstName Trim#0 " John "->"John"`,
+			`call FirstName UpperCase#1 "John"->"JOHN"`,
+			`global FirstName UpperCase#1 "John"->"JOHN"`,
+			`call Addresses.0 Trim#0 " a"->"a"`,
+			`global Addresses.0 Trim#0 " a"->"a"`,
+			`call Addresses Each#0 [" a"]->["a"]`,
+			`global Addresses Each#0 [" a"]->["a"]`,
+		}, calls)
+	}
+
+	calls = nil
+	var to string
+	err = transformation.Transform(" x ", &to, transformation.Trim)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{`global  Trim#0 " x "->"x"`}, calls)
+	}
+}
+
+func TestInterceptorContext(t *testing.T) {
+	type key struct{}
+	var seen []interface{}
+	interceptor := func(ctx context.Context, info transformation.StepInfo, in interface{}, next transformation.Invoker) (interface{}, error) {
+		seen = append(seen, ctx.Value(key{}))
+
+		return next(context.WithValue(ctx, key{}, info.Name), in)
+	}
+
+	from := Person{Addresses: []string{" a"}}
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{
+			transformation.WithContext(context.WithValue(context.Background(), key{}, "root")),
+			transformation.WithInterceptors(interceptor),
+		},
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.Each(transformation.Trim)),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{"root", "Each"}, seen)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	from := Person{FirstName: "John"}
+	var to int64
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{transformation.WithInterceptors(transformation.RecoverPanics)},
+		transformation.Field(&from.FirstName, &to, transformation.Money100),
+	)
+	if assert.Error(t, err) {
+		assert.Equal(t, "FirstName: Money panicked: invalid value type; expected float32 or float64 type but got string.", err.Error())
+	}
+}
@@ -0,0 +1,198 @@
+package transformation
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// Metrics receives measurements of the transformations. Implementations must be safe
+	// for concurrent use.
+	Metrics interface {
+		// ObserveTransformation is called after every Transform and TransformStruct call.
+		ObserveTransformation(duration time.Duration, err error)
+		// ObserveField is called after every TransformStruct rule with the field name
+		// prefixed by the struct name, e.g. Person.FirstName.
+		ObserveField(field string, duration time.Duration, err error)
+		// ObserveStep is called after every transformer invocation, including the ones
+		// nested in transformers such as Each.
+		ObserveStep(info StepInfo, duration time.Duration, err error)
+	}
+
+	// ExpvarMetrics publishes the measurements through the expvar package.
+	ExpvarMetrics struct {
+		transformations        *expvar.Int
+		transformationFailures *expvar.Int
+		transformationLatency  *expvar.Map
+		fields                 *expvar.Map
+		fieldFailures          *expvar.Map
+		fieldLatency           *expvar.Map
+		steps                  *expvar.Map
+		stepFailures           *expvar.Map
+		stepLatency            *expvar.Map
+
+		mu sync.Mutex
+	}
+)
+
+var (
+	// expvarMu serialises the lookup and the publishing of the expvar maps.
+	expvarMu sync.Mutex
+
+	globalMetrics struct {
+		sync.RWMutex
+		metrics Metrics
+	}
+
+	latencyBuckets = []time.Duration{
+		time.Microsecond,
+		10 * time.Microsecond,
+		100 * time.Microsecond,
+		time.Millisecond,
+		10 * time.Millisecond,
+		100 * time.Millisecond,
+		time.Second,
+	}
+)
+
+// SetMetrics sets the metrics used by every transformation. Passing nil disables them.
+func SetMetrics(m Metrics) {
+	globalMetrics.Lock()
+	defer globalMetrics.Unlock()
+
+	globalMetrics.metrics = m
+}
+
+// WithMetrics sets the metrics used by a single TransformStructWithOptions call instead of
+// the ones set with SetMetrics.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// NewExpvarMetrics returns metrics published as an expvar map with the given name. It
+// contains the number of transformations, fields and steps, their failures and latency
+// histograms, keyed by field and transformer name. If a map with the given name was already
+// published, e.g. by an earlier call, it is reused along with the counters it holds. It fails
+// if the name is used by an expvar which is not a map.
+func NewExpvarMetrics(name string) (*ExpvarMetrics, error) {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	var root *expvar.Map
+	switch v := expvar.Get(name).(type) {
+	case nil:
+		root = expvar.NewMap(name)
+	case *expvar.Map:
+		root = v
+	default:
+		return nil, fmt.Errorf("expvar %s is a %T, not a map", name, v)
+	}
+
+	m := &ExpvarMetrics{
+		transformations:        expvarInt(root, "transformations"),
+		transformationFailures: expvarInt(root, "transformation_failures"),
+		transformationLatency:  expvarMap(root, "transformation_latency"),
+		fields:                 expvarMap(root, "fields"),
+		fieldFailures:          expvarMap(root, "field_failures"),
+		fieldLatency:           expvarMap(root, "field_latency"),
+		steps:                  expvarMap(root, "steps"),
+		stepFailures:           expvarMap(root, "step_failures"),
+		stepLatency:            expvarMap(root, "step_latency"),
+	}
+
+	return m, nil
+}
+
+// expvarInt returns the counter stored under the given key, replacing any other value.
+func expvarInt(root *expvar.Map, key string) *expvar.Int {
+	if v, ok := root.Get(key).(*expvar.Int); ok {
+		return v
+	}
+
+	v := new(expvar.Int)
+	root.Set(key, v)
+
+	return v
+}
+
+// expvarMap returns the map stored under the given key, replacing any other value.
+func expvarMap(root *expvar.Map, key string) *expvar.Map {
+	if v, ok := root.Get(key).(*expvar.Map); ok {
+		return v
+	}
+
+	v := new(expvar.Map).Init()
+	root.Set(key, v)
+
+	return v
+}
+
+func (m *ExpvarMetrics) ObserveTransformation(duration time.Duration, err error) {
+	m.transformations.Add(1)
+	if err != nil {
+		m.transformationFailures.Add(1)
+	}
+	observeLatency(m.transformationLatency, duration)
+}
+
+func (m *ExpvarMetrics) ObserveField(field string, duration time.Duration, err error) {
+	m.fields.Add(field, 1)
+	if err != nil {
+		m.fieldFailures.Add(field, 1)
+	}
+	observeLatency(m.histogram(m.fieldLatency, field), duration)
+}
+
+func (m *ExpvarMetrics) ObserveStep(info StepInfo, duration time.Duration, err error) {
+	m.steps.Add(info.Name, 1)
+	if err != nil {
+		m.stepFailures.Add(info.Name, 1)
+	}
+	observeLatency(m.histogram(m.stepLatency, info.Name), duration)
+}
+
+// histogram returns the histogram stored under the given key, creating it if needed.
+func (m *ExpvarMetrics) histogram(histograms *expvar.Map, key string) *expvar.Map {
+	if h, ok := histograms.Get(key).(*expvar.Map); ok {
+		return h
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := histograms.Get(key).(*expvar.Map); ok {
+		return h
+	}
+
+	h := new(expvar.Map).Init()
+	histograms.Set(key, h)
+
+	return h
+}
+
+// observeLatency adds the duration to the histogram. Every bucket counts the durations up to
+// its upper bound which did not fit in the previous buckets.
+func observeLatency(histogram *expvar.Map, duration time.Duration) {
+	bucket := "+Inf"
+	for _, b := range latencyBuckets {
+		if duration <= b {
+			bucket = b.String()
+			break
+		}
+	}
+
+	histogram.Add(bucket, 1)
+	histogram.Add("count", 1)
+	histogram.Add("sum_ns", int64(duration))
+}
+
+func globalMetricsValue() Metrics {
+	globalMetrics.RLock()
+	defer globalMetrics.RUnlock()
+
+	return globalMetrics.metrics
+}
@@ -0,0 +1,103 @@
+package transformation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *recordingMetrics) ObserveTransformation(duration time.Duration, err error) {
+	m.add(fmt.Sprintf("transformation %v", err))
+}
+
+func (m *recordingMetrics) ObserveField(field string, duration time.Duration, err error) {
+	m.add(fmt.Sprintf("field %s %v", field, err))
+}
+
+func (m *recordingMetrics) ObserveStep(info transformation.StepInfo, duration time.Duration, err error) {
+	m.add(fmt.Sprintf("step %s %s %v", info.Field, info.Name, err))
+}
+
+func (m *recordingMetrics) add(event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, event)
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	from := Person{FirstName: " John ", Addresses: []string{"a"}}
+	fail := transformation.By(func(from interface{}) (interface{}, error) {
+		return nil, errors.New("invalid")
+	})
+
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{transformation.WithMetrics(m)},
+		transformation.Field(&from.FirstName, &from.FirstName, transformation.Trim),
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.Each(fail)),
+	)
+	assert.Error(t, err)
+	assert.Equal(t, []string{
+		"step FirstName Trim <nil>",
+		"field Person.FirstName <nil>",
+		"step Addresses.0 By invalid",
+		"step Addresses Each 0: invalid.",
+		"field Person.Addresses 0: invalid.",
+		"transformation Addresses: (0: invalid.).",
+	}, m.events)
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	name := fmt.Sprintf("transformation_test_%d", time.Now().UnixNano())
+	m, err := transformation.NewExpvarMetrics(name)
+	if !assert.NoError(t, err) {
+		return
+	}
+	transformation.SetMetrics(m)
+	defer transformation.SetMetrics(nil)
+
+	var to string
+	assert.NoError(t, transformation.Transform(" a ", &to, transformation.Trim, transformation.UpperCase))
+	assert.Error(t, transformation.Transform([]string{"a"}, &to, transformation.Each(transformation.By(func(from interface{}) (interface{}, error) {
+		return nil, errors.New("invalid")
+	}))))
+
+	var vars struct {
+		Transformations        int                       `json:"transformations"`
+		TransformationFailures int                       `json:"transformation_failures"`
+		Steps                  map[string]int            `json:"steps"`
+		StepFailures           map[string]int            `json:"step_failures"`
+		StepLatency            map[string]map[string]int `json:"step_latency"`
+	}
+	if assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &vars)) {
+		assert.Equal(t, 2, vars.Transformations)
+		assert.Equal(t, 1, vars.TransformationFailures)
+		assert.Equal(t, map[string]int{"Trim": 1, "UpperCase": 1, "Each": 1, "By": 1}, vars.Steps)
+		assert.Equal(t, map[string]int{"Each": 1, "By": 1}, vars.StepFailures)
+		assert.Equal(t, 1, vars.StepLatency["Trim"]["count"])
+	}
+
+	reused, err := transformation.NewExpvarMetrics(name)
+	if assert.NoError(t, err) {
+		reused.ObserveTransformation(time.Millisecond, nil)
+		assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &vars))
+		assert.Equal(t, 3, vars.Transformations)
+	}
+
+	expvar.NewInt(name + "_int")
+	_, err = transformation.NewExpvarMetrics(name + "_int")
+	assert.EqualError(t, err, "expvar "+name+"_int is a *expvar.Int, not a map")
+}
@@ -0,0 +1,48 @@
+package transformation
+
+import (
+	"context"
+)
+
+type (
+	// Option changes the behaviour of TransformStructWithOptions.
+	Option func(o *options)
+
+	options struct {
+		ctx          context.Context
+		atomic       bool
+		changes      *ChangeSet
+		interceptors []Interceptor
+		trace        *TraceNode
+		metrics      Metrics
+		concurrency  int
+	}
+)
+
+// Atomic makes TransformStructWithOptions apply the rules to a staged copy of the values and
+// write the destinations only if every rule succeeds. Otherwise, nothing is written.
+func Atomic() Option {
+	return func(o *options) {
+		o.atomic = true
+	}
+}
+
+// Concurrency makes TransformStructWithOptions apply up to limit rules at the same time. A rule
+// still starts only after the rules writing to its dependencies, and after the rules declared
+// before it which read or write the fields it reads or writes, so the result is the same as
+// applying the rules one after another. Computed fields must only read the fields they declare
+// as dependencies. A rule which panics fails with an error instead of crashing the process.
+func Concurrency(limit int) Option {
+	return func(o *options) {
+		o.concurrency = limit
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
@@ -0,0 +1,577 @@
+package transformation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Operation is a single RFC 6902 JSON Patch operation.
+	Operation struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		From  string      `json:"from,omitempty"`
+		Value interface{} `json:"value,omitempty"`
+	}
+
+	// Patch is an RFC 6902 JSON Patch document.
+	Patch []Operation
+)
+
+// MarshalJSON makes sure the value member is present for the operations which require it,
+// even when the value is null.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		return json.Marshal(struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}{op.Op, op.Path, op.Value})
+	default:
+		type operation Operation
+		return json.Marshal(operation(op))
+	}
+}
+
+// Diff returns the JSON Patch which turns the JSON representation of before into the JSON
+// representation of after. Paths are built from the JSON field names, array indexes and map keys.
+func Diff(before, after interface{}) (Patch, error) {
+	a, err := toJSONDocument(before)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := toJSONDocument(after)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffDocuments(nil, "", a, b), nil
+}
+
+// JSONPatch returns the JSON Patch describing the changes. Changes of destinations outside
+// the transformed struct and of fields which are not serialised are skipped.
+func (cs ChangeSet) JSONPatch() (Patch, error) {
+	var patch Patch
+	for _, change := range cs {
+		if change.Pointer == "" {
+			continue
+		}
+
+		oldEmpty := change.OmitEmpty && isEmptyJSONValue(change.Old)
+		newEmpty := change.OmitEmpty && isEmptyJSONValue(change.New)
+		switch {
+		case oldEmpty && newEmpty:
+			continue
+		case newEmpty:
+			patch = append(patch, Operation{Op: "remove", Path: change.Pointer})
+			continue
+		}
+
+		b, err := toJSONDocument(change.New)
+		if err != nil {
+			return nil, err
+		}
+
+		if oldEmpty {
+			patch = append(patch, Operation{Op: "add", Path: change.Pointer, Value: b})
+			continue
+		}
+
+		a, err := toJSONDocument(change.Old)
+		if err != nil {
+			return nil, err
+		}
+
+		patch = diffDocuments(patch, change.Pointer, a, b)
+	}
+
+	return patch, nil
+}
+
+// ApplyPatch applies the JSON Patch to the JSON representation of the struct the given
+// pointer points to. If any operation fails, the struct is left untouched.
+func ApplyPatch(to interface{}, patch Patch) error {
+	value := reflect.ValueOf(to)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("must be a non-nil pointer to a struct but got %T", to)
+	}
+
+	doc, err := toJSONDocument(to)
+	if err != nil {
+		return err
+	}
+
+	for i, op := range patch {
+		if doc, err = applyOperation(doc, op); err != nil {
+			return fmt.Errorf("operation %d: %v", i, err)
+		}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	tmp := reflect.New(value.Elem().Type())
+	tmp.Elem().Set(value.Elem())
+	resetJSONFields(tmp.Elem())
+	if err := json.Unmarshal(b, tmp.Interface()); err != nil {
+		return err
+	}
+	value.Elem().Set(tmp.Elem())
+
+	return nil
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := toJSONDocument(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return patchAdd(doc, path, value)
+	case "remove":
+		doc, _, err := patchRemove(doc, path)
+
+		return doc, err
+	case "replace":
+		value, err := toJSONDocument(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if doc, _, err = patchRemove(doc, path); err != nil {
+			return nil, err
+		}
+
+		return patchAdd(doc, path, value)
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if op.Op == "move" {
+			doc, value, err = patchRemove(doc, from)
+		} else {
+			value, err = patchGet(doc, from)
+			if err == nil {
+				value, err = toJSONDocument(value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return patchAdd(doc, path, value)
+	case "test":
+		expected, err := toJSONDocument(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		actual, err := patchGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			return nil, fmt.Errorf("test failed for %q", op.Path)
+		}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+func diffDocuments(patch Patch, path string, a, b interface{}) Patch {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			return append(patch, Operation{Op: "replace", Path: path, Value: b})
+		}
+
+		for _, key := range sortedKeys(av) {
+			if _, ok := bv[key]; !ok {
+				patch = append(patch, Operation{Op: "remove", Path: path + "/" + escapeJSONPointer(key)})
+			}
+		}
+
+		for _, key := range sortedKeys(bv) {
+			p := path + "/" + escapeJSONPointer(key)
+			if v, ok := av[key]; ok {
+				patch = diffDocuments(patch, p, v, bv[key])
+				continue
+			}
+			patch = append(patch, Operation{Op: "add", Path: p, Value: bv[key]})
+		}
+
+		return patch
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			return append(patch, Operation{Op: "replace", Path: path, Value: b})
+		}
+
+		n := len(av)
+		if len(bv) < n {
+			n = len(bv)
+		}
+
+		for i := 0; i < n; i++ {
+			patch = diffDocuments(patch, path+"/"+strconv.Itoa(i), av[i], bv[i])
+		}
+
+		for i := n; i < len(bv); i++ {
+			patch = append(patch, Operation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: bv[i]})
+		}
+
+		for i := len(av) - 1; i >= n; i-- {
+			patch = append(patch, Operation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+
+		return patch
+	default:
+		if !reflect.DeepEqual(a, b) {
+			patch = append(patch, Operation{Op: "replace", Path: path, Value: b})
+		}
+
+		return patch
+	}
+}
+
+func patchGet(node interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return node, nil
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", path[0])
+		}
+
+		return patchGet(child, path[1:])
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		return patchGet(n[i], path[1:])
+	default:
+		return nil, fmt.Errorf("cannot reference %q in a scalar value", path[0])
+	}
+}
+
+func patchAdd(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			n[path[0]] = value
+
+			return n, nil
+		}
+
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", path[0])
+		}
+
+		child, err := patchAdd(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = child
+
+		return n, nil
+	case []interface{}:
+		if len(path) == 1 {
+			i := len(n)
+			if path[0] != "-" {
+				var err error
+				if i, err = arrayIndex(path[0], len(n)); err != nil {
+					return nil, err
+				}
+			}
+
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+
+			return n, nil
+		}
+
+		i, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		if n[i], err = patchAdd(n[i], path[1:], value); err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot add %q to a scalar value", path[0])
+	}
+}
+
+func patchRemove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", path[0])
+		}
+
+		if len(path) == 1 {
+			delete(n, path[0])
+
+			return n, child, nil
+		}
+
+		child, removed, err := patchRemove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[path[0]] = child
+
+		return n, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(path) == 1 {
+			removed := n[i]
+
+			return append(n[:i], n[i+1:]...), removed, nil
+		}
+
+		child, removed, err := patchRemove(n[i], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[i] = child
+
+		return n, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("cannot remove %q from a scalar value", path[0])
+	}
+}
+
+// arrayIndex parses an array index token which must be between 0 and max.
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	return i, nil
+}
+
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	path := strings.Split(pointer[1:], "/")
+	for i, token := range path {
+		path[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	return path, nil
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// toJSONDocument returns the generic JSON representation of the given value.
+func toJSONDocument(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// resetJSONFields sets every struct field which is decoded from JSON to its zero value, so
+// decoding does not merge into existing maps, slices or pointers.
+func resetJSONFields(structValue reflect.Value) {
+	for i := 0; i < structValue.NumField(); i++ {
+		sf := structValue.Type().Field(i)
+		f := structValue.Field(i)
+		name, ok := jsonFieldName(sf)
+		if !ok || !f.CanSet() {
+			continue
+		}
+
+		if sf.Anonymous && name == "" {
+			switch {
+			case sf.Type.Kind() == reflect.Struct:
+				resetJSONFields(f)
+				continue
+			case isEmbeddedStructPtr(sf):
+				if !f.IsNil() {
+					cp := reflect.New(sf.Type.Elem())
+					cp.Elem().Set(f.Elem())
+					resetJSONFields(cp.Elem())
+					f.Set(cp)
+				}
+				continue
+			}
+		}
+
+		f.Set(reflect.Zero(sf.Type))
+	}
+}
+
+// jsonFieldName returns the name from the json tag of the field. An empty name is returned for
+// embedded structs which are not named by a tag. False is returned if the field is not serialised.
+func jsonFieldName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if sf.Anonymous && name == "" {
+		t := sf.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true
+		}
+	}
+
+	if sf.PkgPath != "" {
+		return "", false
+	}
+
+	if name == "" {
+		name = sf.Name
+	}
+
+	return name, true
+}
+
+// jsonPointer returns the JSON pointer of the struct field the given pointer points to and
+// whether the field is tagged with omitempty.
+func jsonPointer(structValue reflect.Value, fieldValue reflect.Value) (string, bool, bool) {
+	ptr := fieldValue.Pointer()
+	for i := structValue.NumField() - 1; i >= 0; i-- {
+		sf := structValue.Type().Field(i)
+		name, ok := jsonFieldName(sf)
+		if !ok {
+			continue
+		}
+
+		if ptr == structValue.Field(i).UnsafeAddr() && sf.Type == fieldValue.Elem().Type() && name != "" {
+			return "/" + escapeJSONPointer(name), strings.Contains(sf.Tag.Get("json"), ",omitempty"), true
+		}
+
+		if !sf.Anonymous {
+			continue
+		}
+
+		fi := structValue.Field(i)
+		if sf.Type.Kind() == reflect.Ptr {
+			if fi.IsNil() {
+				continue
+			}
+			fi = fi.Elem()
+		}
+		if fi.Kind() != reflect.Struct {
+			continue
+		}
+
+		if pointer, omitEmpty, ok := jsonPointer(fi, fieldValue); ok {
+			if name != "" {
+				pointer = "/" + escapeJSONPointer(name) + pointer
+			}
+
+			return pointer, omitEmpty, true
+		}
+	}
+
+	return "", false, false
+}
+
+// isEmptyJSONValue reports whether the value is omitted by encoding/json when tagged with omitempty.
+func isEmptyJSONValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return rv.IsNil()
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
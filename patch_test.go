@@ -0,0 +1,152 @@
+package transformation_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+type (
+	Meta struct {
+		Source string `json:"source"`
+	}
+
+	Order struct {
+		Meta
+		ID       int               `json:"id"`
+		Customer string            `json:"customer_name"`
+		Notes    string            `json:"notes,omitempty"`
+		Tags     []string          `json:"tags"`
+		Attrs    map[string]string `json:"attrs"`
+		Internal string            `json:"-"`
+	}
+)
+
+func TestDiff(t *testing.T) {
+	before := Order{
+		Meta:     Meta{Source: " web "},
+		ID:       1,
+		Customer: " John ",
+		Tags:     []string{" a ", "b", "c"},
+		Attrs:    map[string]string{"color": " red ", "size": "L"},
+	}
+
+	after, err := transformation.TransformedCopy(
+		&before,
+		transformation.Field(&before.Source, &before.Source, transformation.Trim),
+		transformation.Field(&before.Customer, &before.Customer, transformation.Trim),
+		transformation.Field(&before.Tags, &before.Tags, transformation.Each(transformation.Trim)),
+		transformation.Field(&before.Attrs, &before.Attrs, transformation.Each(transformation.Trim)),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	patch, err := transformation.Diff(before, after)
+	if assert.NoError(t, err) {
+		b, err := json.Marshal(patch)
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `[
+				{"op":"replace","path":"/attrs/color","value":"red"},
+				{"op":"replace","path":"/customer_name","value":"John"},
+				{"op":"replace","path":"/source","value":"web"},
+				{"op":"replace","path":"/tags/0","value":"a"}
+			]`, string(b))
+		}
+	}
+
+	patch, err = transformation.Diff(Order{Tags: []string{"a", "b", "c"}}, Order{Tags: []string{"a"}, Notes: "n"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, transformation.Patch{
+			{Op: "add", Path: "/notes", Value: "n"},
+			{Op: "remove", Path: "/tags/2"},
+			{Op: "remove", Path: "/tags/1"},
+		}, patch)
+	}
+}
+
+func TestChangeSetJSONPatch(t *testing.T) {
+	o := Order{
+		Meta:     Meta{Source: " web "},
+		Customer: " John ",
+		Notes:    "  ",
+		Tags:     []string{" a ", "b"},
+		Internal: " x ",
+	}
+
+	var cs transformation.ChangeSet
+	err := transformation.TransformStructWithOptions(
+		&o,
+		[]transformation.Option{transformation.RecordChanges(&cs)},
+		transformation.Field(&o.Source, &o.Source, transformation.Trim),
+		transformation.Field(&o.Customer, &o.Customer, transformation.Trim),
+		transformation.Field(&o.Notes, &o.Notes, transformation.Trim),
+		transformation.Field(&o.Tags, &o.Tags, transformation.Each(transformation.Trim)),
+		transformation.Field(&o.Internal, &o.Internal, transformation.Trim),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	patch, err := cs.JSONPatch()
+	if assert.NoError(t, err) {
+		assert.Equal(t, transformation.Patch{
+			{Op: "replace", Path: "/source", Value: "web"},
+			{Op: "replace", Path: "/customer_name", Value: "John"},
+			{Op: "remove", Path: "/notes"},
+			{Op: "replace", Path: "/tags/0", Value: "a"},
+		}, patch)
+	}
+
+	b, err := json.Marshal(cs)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var decoded transformation.ChangeSet
+	if assert.NoError(t, json.Unmarshal(b, &decoded)) {
+		decodedPatch, err := decoded.JSONPatch()
+		if assert.NoError(t, err) {
+			assert.Equal(t, patch, decodedPatch)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	o := Order{
+		ID:       1,
+		Customer: " John ",
+		Tags:     []string{"a", "b"},
+		Attrs:    map[string]string{"color": "red", "size": "L"},
+		Internal: "keep",
+	}
+	attrs := o.Attrs
+
+	err := transformation.ApplyPatch(&o, transformation.Patch{
+		{Op: "test", Path: "/id", Value: 1},
+		{Op: "replace", Path: "/customer_name", Value: "John"},
+		{Op: "add", Path: "/tags/-", Value: "c"},
+		{Op: "remove", Path: "/tags/0"},
+		{Op: "remove", Path: "/attrs/size"},
+		{Op: "copy", From: "/attrs/color", Path: "/source"},
+		{Op: "move", From: "/customer_name", Path: "/notes"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "red", o.Source)
+		assert.Equal(t, "", o.Customer)
+		assert.Equal(t, "John", o.Notes)
+		assert.Equal(t, []string{"b", "c"}, o.Tags)
+		assert.Equal(t, map[string]string{"color": "red"}, o.Attrs)
+		assert.Equal(t, "keep", o.Internal)
+		assert.Len(t, attrs, 2)
+	}
+
+	err = transformation.ApplyPatch(&o, transformation.Patch{
+		{Op: "replace", Path: "/customer_name", Value: "Jane"},
+		{Op: "test", Path: "/id", Value: 2},
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "", o.Customer)
+	}
+}
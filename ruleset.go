@@ -0,0 +1,323 @@
+package transformation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TagName is the struct tag holding the pipeline of a field, e.g. `transform:"trim|down_case"`.
+const TagName = "transform"
+
+type (
+	// RuleSet is a named list of field pipelines written in the pipeline DSL. Unlike rules
+	// built with Field, it is not bound to a struct value and can be applied to any value of
+	// the struct it was created for.
+	RuleSet struct {
+		Name   string       `json:"name"`
+		Fields []FieldRules `json:"fields"`
+	}
+
+	// FieldRules is the pipeline of a single field of a RuleSet.
+	FieldRules struct {
+		// Field is the name of the struct field.
+		Field string `json:"field"`
+		// JSONName is the name of the field in the JSON representation of the struct.
+		JSONName string `json:"json_name,omitempty"`
+		// Pipeline is the pipeline written in the pipeline DSL.
+		Pipeline string `json:"pipeline"`
+
+		transformers []Transformer
+	}
+)
+
+var ruleSets = struct {
+	sync.RWMutex
+	sets map[string]*RuleSet
+}{
+	sets: map[string]*RuleSet{},
+}
+
+// NewRuleSet returns a rule set with the given fields, parsing their pipelines.
+func NewRuleSet(name string, fields ...FieldRules) (*RuleSet, error) {
+	rs := &RuleSet{Name: name, Fields: make([]FieldRules, len(fields))}
+	for i, field := range fields {
+		if field.Field == "" {
+			return nil, errors.New("field name is missing")
+		}
+
+		transformers, err := ParsePipeline(field.Pipeline)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field.Field, err)
+		}
+
+		field.transformers = transformers
+		rs.Fields[i] = field
+	}
+
+	return rs, nil
+}
+
+// RuleSetFromTags returns a rule set built from the transform tags of the given struct or
+// struct pointer. The rule set is named after the struct type.
+func RuleSetFromTags(v interface{}) (*RuleSet, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must be a struct or a pointer to a struct but got %T", v)
+	}
+
+	return NewRuleSet(t.Name(), tagFieldRules(t)...)
+}
+
+// LoadRuleSet reads a rule set from its JSON representation, e.g.
+// {"name": "Person", "fields": [{"field": "Email", "pipeline": "trim|down_case"}]}.
+func LoadRuleSet(r io.Reader) (*RuleSet, error) {
+	var rs RuleSet
+	if err := json.NewDecoder(r).Decode(&rs); err != nil {
+		return nil, err
+	}
+
+	return NewRuleSet(rs.Name, rs.Fields...)
+}
+
+// RegisterRuleSet makes the rule set available under its name, replacing any rule set
+// registered with the same name.
+func RegisterRuleSet(rs *RuleSet) {
+	ruleSets.Lock()
+	defer ruleSets.Unlock()
+
+	ruleSets.sets[rs.Name] = rs
+}
+
+// LookupRuleSet returns the registered rule set with the given name.
+func LookupRuleSet(name string) (*RuleSet, bool) {
+	ruleSets.RLock()
+	defer ruleSets.RUnlock()
+
+	rs, ok := ruleSets.sets[name]
+
+	return rs, ok
+}
+
+// RegisteredRuleSets returns the registered rule sets sorted by name.
+func RegisteredRuleSets() []*RuleSet {
+	ruleSets.RLock()
+	defer ruleSets.RUnlock()
+
+	sets := make([]*RuleSet, 0, len(ruleSets.sets))
+	for _, rs := range ruleSets.sets {
+		sets = append(sets, rs)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Name < sets[j].Name
+	})
+
+	return sets
+}
+
+// Rules returns the rules of the rule set bound to the struct the given pointer points to.
+// Every field is transformed in place. Fields are looked up by their name, then by their
+// JSON name.
+func (rs *RuleSet) Rules(structPtr interface{}) ([]Rule, error) {
+	value := reflect.ValueOf(structPtr)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must be a non-nil pointer to a struct but got %T", structPtr)
+	}
+
+	rules := make([]Rule, len(rs.Fields))
+	for i, field := range rs.Fields {
+		f, ok := lookupField(value.Elem(), field)
+		if !ok {
+			return nil, fmt.Errorf("field %s not found in %s", field.Field, value.Elem().Type())
+		}
+
+		ptr := f.Addr().Interface()
+		rules[i] = Field(ptr, ptr, field.transformers...)
+	}
+
+	return rules, nil
+}
+
+// TransformMap applies the rule set to a map keyed by JSON names, e.g. a decoded JSON object.
+// Fields without a JSON name are looked up by their name. A missing key is only set if its
+// pipeline produces a non-zero value for it, e.g. a default. The failures are returned as
+// Errors keyed by field name.
+func (rs *RuleSet) TransformMap(m map[string]interface{}) error {
+	return rs.TransformMapWithOptions(m, nil)
+}
+
+// TransformMapWithOptions is like TransformMap but its behaviour can be changed with the given
+// options. The options which only apply to structs, RecordChanges, WithTrace and Concurrency,
+// are ignored.
+func (rs *RuleSet) TransformMapWithOptions(m map[string]interface{}, opts []Option) error {
+	o := newOptions(opts)
+
+	start := time.Now()
+	e := newExecution(o.ctx, o.interceptors)
+	if o.metrics != nil {
+		e.metrics = o.metrics
+	}
+
+	target := m
+	if o.atomic {
+		target = deepCopy(reflect.ValueOf(m)).Interface().(map[string]interface{})
+	}
+
+	err := rs.transformMap(e, target)
+	e.observe(start, err)
+	if err == nil && o.atomic {
+		for k, v := range target {
+			m[k] = v
+		}
+	}
+
+	return err
+}
+
+func (rs *RuleSet) transformMap(e *execution, m map[string]interface{}) error {
+	errs := Errors{}
+	for _, field := range rs.Fields {
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+
+		key := field.key()
+		from, ok := m[key]
+
+		to, err := e.field(field.Field).transform(from, field.transformers)
+		if err != nil {
+			errs[field.Field] = err
+			continue
+		}
+
+		if !ok && (to == nil || reflect.ValueOf(to).IsZero()) {
+			continue
+		}
+		m[key] = to
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Transformers returns the transformers of the field pipeline.
+func (f FieldRules) Transformers() []Transformer {
+	return f.transformers
+}
+
+// key returns the name of the field in the JSON representation of the struct.
+func (f FieldRules) key() string {
+	if f.JSONName != "" {
+		return f.JSONName
+	}
+
+	return f.Field
+}
+
+// lookupField returns the struct field with the name of the rules, falling back to the field
+// with the same JSON name.
+func lookupField(structValue reflect.Value, field FieldRules) (reflect.Value, bool) {
+	if sf, ok := structValue.Type().FieldByName(field.Field); ok && sf.PkgPath == "" {
+		if f, ok := fieldByIndex(structValue, sf.Index); ok {
+			return f, true
+		}
+	}
+
+	name := field.key()
+	for _, sf := range jsonFields(structValue.Type()) {
+		if n, ok := jsonFieldName(sf); ok && n == name {
+			if f, ok := fieldByIndex(structValue, sf.Index); ok {
+				return f, true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// FieldRulesFromTag returns the rules of the struct field built from its transform tag, along
+// with the field name from its json tag. It reports false if the field has no transform tag or
+// if the tag is "-".
+func FieldRulesFromTag(sf reflect.StructField) (FieldRules, bool) {
+	pipeline, ok := sf.Tag.Lookup(TagName)
+	if !ok || pipeline == "-" {
+		return FieldRules{}, false
+	}
+
+	name, _ := jsonFieldName(sf)
+
+	return FieldRules{
+		Field:    sf.Name,
+		JSONName: name,
+		Pipeline: pipeline,
+	}, true
+}
+
+// tagFieldRules returns the rules of the fields of the struct type which have a transform tag,
+// including the fields promoted from embedded structs.
+func tagFieldRules(t reflect.Type) []FieldRules {
+	var fields []FieldRules
+	for _, sf := range jsonFields(t) {
+		if field, ok := FieldRulesFromTag(sf); ok {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// jsonFields returns the exported fields of the struct type, replacing embedded structs which
+// are not named by a json tag with their own fields. The index of every field is relative to t.
+func jsonFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if name, ok := jsonFieldName(sf); ok && sf.Anonymous && name == "" {
+			et := sf.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+
+			for _, esf := range jsonFields(et) {
+				esf.Index = append([]int{i}, esf.Index...)
+				fields = append(fields, esf)
+			}
+			continue
+		}
+
+		if sf.PkgPath == "" {
+			fields = append(fields, sf)
+		}
+	}
+
+	return fields
+}
+
+// fieldByIndex returns the nested field of the struct with the given index. It reports false
+// if the field is reached through a nil embedded pointer.
+func fieldByIndex(structValue reflect.Value, index []int) (reflect.Value, bool) {
+	v := structValue
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
@@ -0,0 +1,169 @@
+package transformation_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	Contact struct {
+		Phone string `json:"phone" transform:"trim|default(\"unknown\")"`
+	}
+
+	Signup struct {
+		Contact
+		Email    string   `json:"email" transform:"trim|down_case"`
+		Name     string   `json:"-" transform:"trim|upper_case"`
+		Tags     []string `json:"tags" transform:"each(trim)"`
+		Password string   `json:"password"`
+	}
+)
+
+func TestRuleSetFromTags(t *testing.T) {
+	rs, err := transformation.RuleSetFromTags(Signup{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Signup", rs.Name)
+	assert.Equal(t, []transformation.FieldRules{
+		{Field: "Phone", JSONName: "phone", Pipeline: `trim|default("unknown")`},
+		{Field: "Email", JSONName: "email", Pipeline: "trim|down_case"},
+		{Field: "Name", Pipeline: "trim|upper_case"},
+		{Field: "Tags", JSONName: "tags", Pipeline: "each(trim)"},
+	}, stripTransformers(rs.Fields))
+
+	s := &Signup{
+		Email: " John@Example.COM ",
+		Name:  " john ",
+		Tags:  []string{" a ", "b "},
+	}
+
+	rules, err := rs.Rules(s)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.NoError(t, transformation.TransformStructRules(s, rules...)) {
+		assert.Equal(t, &Signup{
+			Contact: Contact{Phone: "unknown"},
+			Email:   "john@example.com",
+			Name:    "JOHN",
+			Tags:    []string{"a", "b"},
+		}, s)
+	}
+
+	_, err = transformation.RuleSetFromTags("signup")
+	assert.EqualError(t, err, "must be a struct or a pointer to a struct but got string")
+}
+
+func TestFieldRulesFromTag(t *testing.T) {
+	field, ok := transformation.FieldRulesFromTag(reflect.StructField{Name: "Email", Tag: `json:"email,omitempty" transform:"trim"`})
+	if assert.True(t, ok) {
+		assert.Equal(t, transformation.FieldRules{Field: "Email", JSONName: "email", Pipeline: "trim"}, field)
+	}
+
+	_, ok = transformation.FieldRulesFromTag(reflect.StructField{Name: "Email", Tag: `transform:"-"`})
+	assert.False(t, ok)
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	rs, err := transformation.LoadRuleSet(strings.NewReader(`{
+		"name": "Signup",
+		"fields": [
+			{"field": "Email", "pipeline": "trim|down_case"},
+			{"field": "phone", "pipeline": "trim"}
+		]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	s := &Signup{
+		Contact: Contact{Phone: " 555 "},
+		Email:   " A@B.C",
+	}
+
+	rules, err := rs.Rules(s)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.NoError(t, transformation.TransformStructRules(s, rules...)) {
+		assert.Equal(t, "555", s.Phone)
+		assert.Equal(t, "a@b.c", s.Email)
+	}
+
+	_, err = transformation.LoadRuleSet(strings.NewReader(`{"name": "Signup", "fields": [{"field": "Email", "pipeline": "trim|nope"}]}`))
+	assert.EqualError(t, err, `Email: unknown transformer "nope"`)
+
+	rs, err = transformation.NewRuleSet("Signup", transformation.FieldRules{Field: "Missing", Pipeline: "trim"})
+	if assert.NoError(t, err) {
+		_, err = rs.Rules(s)
+		assert.EqualError(t, err, "field Missing not found in transformation_test.Signup")
+	}
+}
+
+func TestRegisterRuleSet(t *testing.T) {
+	contact, err := transformation.RuleSetFromTags(&Contact{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	signup, err := transformation.RuleSetFromTags(&Signup{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	transformation.RegisterRuleSet(signup)
+	transformation.RegisterRuleSet(contact)
+
+	assert.Equal(t, []*transformation.RuleSet{contact, signup}, transformation.RegisteredRuleSets())
+
+	rs, ok := transformation.LookupRuleSet("Signup")
+	if assert.True(t, ok) {
+		assert.Equal(t, signup, rs)
+	}
+}
+
+func TestRuleSetTransformMap(t *testing.T) {
+	rs, err := transformation.NewRuleSet("signup",
+		transformation.FieldRules{Field: "Email", JSONName: "email", Pipeline: "trim|down_case"},
+		transformation.FieldRules{Field: "Phone", JSONName: "phone", Pipeline: `default("unknown")`},
+		transformation.FieldRules{Field: "Born", JSONName: "born", Pipeline: `parse_time("2006-01-02")`},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	m := map[string]interface{}{"email": " A@B.C "}
+	if assert.NoError(t, rs.TransformMap(m)) {
+		assert.Equal(t, map[string]interface{}{"email": "a@b.c", "phone": "unknown"}, m)
+	}
+
+	m = map[string]interface{}{"email": " A@B.C ", "born": "yesterday"}
+	err = rs.TransformMapWithOptions(m, []transformation.Option{transformation.Atomic()})
+	if assert.Error(t, err) {
+		assert.Equal(t, map[string]interface{}{"email": " A@B.C ", "born": "yesterday"}, m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m = map[string]interface{}{"email": " A@B.C "}
+	err = rs.TransformMapWithOptions(m, []transformation.Option{transformation.WithContext(ctx)})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, map[string]interface{}{"email": " A@B.C "}, m)
+}
+
+func stripTransformers(fields []transformation.FieldRules) []transformation.FieldRules {
+	stripped := make([]transformation.FieldRules, len(fields))
+	for i, f := range fields {
+		stripped[i] = transformation.FieldRules{Field: f.Field, JSONName: f.JSONName, Pipeline: f.Pipeline}
+	}
+
+	return stripped
+}
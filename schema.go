@@ -0,0 +1,75 @@
+package transformation
+
+import "time"
+
+// SchemaAnnotator is implemented by transformers which add keywords to the JSON Schema of the
+// field they transform, e.g. a format hint.
+type SchemaAnnotator interface {
+	// SchemaAnnotations returns the JSON Schema keywords added by the transformer.
+	SchemaAnnotations() map[string]interface{}
+}
+
+// JSONSchema returns a JSON Schema fragment per field of the rule set, keyed by its JSON name,
+// which can be merged into the properties of a generated schema. Every fragment lists the
+// steps of the pipeline under x-transform, along with the keywords of the steps implementing
+// SchemaAnnotator, e.g. {"default": "n/a", "x-transform": ["Trim", "Default(value=\"n/a\")"]}.
+func (rs *RuleSet) JSONSchema() map[string]map[string]interface{} {
+	properties := make(map[string]map[string]interface{}, len(rs.Fields))
+	for _, field := range rs.Fields {
+		name := field.key()
+		fragment := schemaAnnotations(field.transformers)
+		if len(field.transformers) > 0 {
+			steps := make([]string, len(field.transformers))
+			for i, t := range field.transformers {
+				steps[i] = formatStep(describeTransformer(t))
+			}
+			fragment["x-transform"] = steps
+		}
+
+		properties[name] = fragment
+	}
+
+	return properties
+}
+
+// schemaAnnotations merges the keywords of the transformers of the pipeline. The keywords of
+// a step override the ones of the steps before it.
+func schemaAnnotations(transformers []Transformer) map[string]interface{} {
+	annotations := map[string]interface{}{}
+	for _, t := range transformers {
+		if a, ok := t.(SchemaAnnotator); ok {
+			for k, v := range a.SchemaAnnotations() {
+				annotations[k] = v
+			}
+		}
+	}
+
+	return annotations
+}
+
+// SchemaAnnotations returns the JSON Schema format matching the layout, date-time for RFC3339
+// layouts and date for "2006-01-02". Other layouts only produce x-time-layout since values
+// written with them would not validate against either format.
+func (t ParseTimeTransformer) SchemaAnnotations() map[string]interface{} {
+	switch t.layout {
+	case time.RFC3339, time.RFC3339Nano:
+		return map[string]interface{}{"format": "date-time"}
+	case "2006-01-02":
+		return map[string]interface{}{"format": "date"}
+	default:
+		return map[string]interface{}{"x-time-layout": t.layout}
+	}
+}
+
+func (t defaultTransformer) SchemaAnnotations() map[string]interface{} {
+	return map[string]interface{}{"default": t.value}
+}
+
+func (t eachTransformer) SchemaAnnotations() map[string]interface{} {
+	items := schemaAnnotations(t.transformers)
+	if len(items) == 0 {
+		return nil
+	}
+
+	return map[string]interface{}{"items": items}
+}
@@ -0,0 +1,34 @@
+package transformation_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+func TestRuleSetJSONSchema(t *testing.T) {
+	rs, err := transformation.NewRuleSet("Signup",
+		transformation.FieldRules{Field: "Email", JSONName: "email", Pipeline: "trim|down_case"},
+		transformation.FieldRules{Field: "Country", JSONName: "country", Pipeline: `upper_case|default("RO")`},
+		transformation.FieldRules{Field: "CreatedAt", JSONName: "created_at", Pipeline: `parse_time("2006-01-02T15:04:05Z07:00")`},
+		transformation.FieldRules{Field: "Holidays", JSONName: "holidays", Pipeline: `each(parse_time("2006-01-02"))`},
+		transformation.FieldRules{Field: "Seen", Pipeline: `parse_time("02/01/2006")`},
+		transformation.FieldRules{Field: "Notes", JSONName: "notes"},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err := json.Marshal(rs.JSONSchema())
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"email": {"x-transform": ["Trim", "DownCase"]},
+			"country": {"default": "RO", "x-transform": ["UpperCase", "Default(value=\"RO\")"]},
+			"created_at": {"format": "date-time", "x-transform": ["ParseTime(layout=\"2006-01-02T15:04:05Z07:00\")"]},
+			"holidays": {"items": {"format": "date"}, "x-transform": ["Each(ParseTime(layout=\"2006-01-02\"))"]},
+			"Seen": {"x-time-layout": "02/01/2006", "x-transform": ["ParseTime(layout=\"02/01/2006\")"]},
+			"notes": {}
+		}`, string(b))
+	}
+}
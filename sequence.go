@@ -0,0 +1,157 @@
+package transformation
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+)
+
+type (
+	// Iterator is a sequence of values of unknown length which Each transforms lazily.
+	Iterator interface {
+		// Next returns the next value of the sequence, or false once it is exhausted.
+		Next() (interface{}, bool)
+		// Err returns the error which ended the sequence, if any.
+		Err() error
+	}
+
+	// Sequence is the result of Each for a channel or an Iterator. A value is only read from
+	// the input once there is room for its result, so a slow consumer slows the producer down.
+	// Both channels are closed when the input is exhausted or the context of the transformation
+	// is done, and they have to be received from until then, e.g. in a select. A consumer which
+	// stops early has to cancel the context, given with TransformContext or WithContext, to
+	// release the goroutines of the Sequence.
+	Sequence struct {
+		// Values receives the transformed values in the order of the input.
+		Values <-chan interface{}
+		// Errors receives Errors keyed by position for the values which could not be
+		// transformed, followed by the error of the Iterator, if any.
+		Errors <-chan error
+	}
+
+	sequenceResult struct {
+		value interface{}
+		err   error
+	}
+
+	// channelIterator reads the values of a channel until it is closed or the context is done.
+	channelIterator struct {
+		ctx context.Context
+		ch  reflect.Value
+	}
+)
+
+var (
+	iteratorType = reflect.TypeOf((*Iterator)(nil)).Elem()
+	sequenceType = reflect.TypeOf(Sequence{})
+)
+
+// TransformContext is like Transform but stops the transformation once the context is done.
+// A channel or an Iterator stops being read, and the channels of its Sequence are closed.
+func (t eachTransformer) TransformContext(ctx context.Context, from interface{}) (interface{}, error) {
+	return t.transformIn(newExecution(ctx, nil), from)
+}
+
+// transformSequence transforms the values of the iterator in the background, on as many
+// goroutines as the transformer has workers. The values keep their order.
+func (t eachTransformer) transformSequence(e *execution, it Iterator) (interface{}, error) {
+	// the values are transformed after the transformation returns, so they are not traced
+	c := *e
+	c.trace = nil
+	e = &c
+
+	workers := t.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	values := make(chan interface{})
+	errs := make(chan error)
+
+	// every value being transformed has a pending result; together with the one waited on
+	// for emission, there are at most as many as workers
+	pending := make(chan chan sequenceResult, workers-1)
+
+	go func() {
+		defer close(pending)
+
+		for i := 0; e.ctx.Err() == nil; i++ {
+			v, ok := it.Next()
+			if !ok {
+				if err := it.Err(); err != nil {
+					res := make(chan sequenceResult, 1)
+					res <- sequenceResult{err: err}
+					select {
+					case pending <- res:
+					case <-e.ctx.Done():
+					}
+				}
+
+				return
+			}
+
+			res := make(chan sequenceResult, 1)
+			select {
+			case pending <- res:
+			case <-e.ctx.Done():
+				return
+			}
+
+			key := strconv.Itoa(i)
+			go func() {
+				out, err := e.child(key).transform(v, t.transformers)
+				if err != nil {
+					err = Errors{key: err}
+				}
+				res <- sequenceResult{value: out, err: err}
+			}()
+		}
+	}()
+
+	go func() {
+		defer close(values)
+		defer close(errs)
+
+		for res := range pending {
+			var r sequenceResult
+			select {
+			case r = <-res:
+			case <-e.ctx.Done():
+				return
+			}
+
+			if r.err != nil {
+				select {
+				case errs <- r.err:
+				case <-e.ctx.Done():
+					return
+				}
+				continue
+			}
+
+			select {
+			case values <- r.value:
+			case <-e.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return Sequence{Values: values, Errors: errs}, nil
+}
+
+func (it *channelIterator) Next() (interface{}, bool) {
+	chosen, v, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: it.ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(it.ctx.Done())},
+	})
+	if chosen != 0 || !ok {
+		return nil, false
+	}
+
+	return v.Interface(), true
+}
+
+func (it *channelIterator) Err() error {
+	return nil
+}
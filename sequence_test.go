@@ -0,0 +1,227 @@
+package transformation_test
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type sliceIterator struct {
+	values []interface{}
+	err    error
+	read   int32
+}
+
+func (it *sliceIterator) Next() (interface{}, bool) {
+	i := atomic.AddInt32(&it.read, 1) - 1
+	if int(i) >= len(it.values) {
+		return nil, false
+	}
+
+	return it.values[i], true
+}
+
+func (it *sliceIterator) Err() error {
+	return it.err
+}
+
+func collectSequence(t *testing.T, v interface{}) ([]interface{}, []error) {
+	seq, ok := v.(transformation.Sequence)
+	if !assert.True(t, ok, "expected a Sequence but got %T", v) {
+		t.FailNow()
+	}
+
+	var values []interface{}
+	var errs []error
+	valuesCh, errsCh := seq.Values, seq.Errors
+	for valuesCh != nil || errsCh != nil {
+		select {
+		case v, ok := <-valuesCh:
+			if !ok {
+				valuesCh = nil
+				continue
+			}
+			values = append(values, v)
+		case err, ok := <-errsCh:
+			if !ok {
+				errsCh = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	return values, errs
+}
+
+func TestEachChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan string, 3)
+	ch <- " a "
+	ch <- "1"
+	ch <- " c"
+	close(ch)
+
+	out, err := transformation.Each(transformation.Trim, transformation.By(func(from interface{}) (interface{}, error) {
+		if from == "1" {
+			return nil, errors.New("digit")
+		}
+
+		return strings.ToUpper(from.(string)), nil
+	})).TransformContext(ctx, ch)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	values, errs := collectSequence(t, out)
+	assert.Equal(t, []interface{}{"A", "C"}, values)
+	assert.Equal(t, []error{transformation.Errors{"1": errors.New("digit")}}, errs)
+
+	_, err = transformation.Each(transformation.Trim).TransformContext(ctx, (chan<- string)(ch))
+	assert.EqualError(t, err, "must be a channel which can be received from")
+
+	var seq transformation.Sequence
+	ch = make(chan string, 2)
+	ch <- " b "
+	ch <- " d"
+	close(ch)
+	if assert.NoError(t, transformation.Transform(ch, &seq, transformation.Each(transformation.Trim))) {
+		values, errs = collectSequence(t, seq)
+		assert.Equal(t, []interface{}{"b", "d"}, values)
+		assert.Empty(t, errs)
+	}
+}
+
+func TestEachIterator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	it := &sliceIterator{values: []interface{}{" a", "b "}, err: errors.New("connection lost")}
+
+	out, err := transformation.Each(transformation.Trim).TransformContext(ctx, it)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	values, errs := collectSequence(t, out)
+	assert.Equal(t, []interface{}{"a", "b"}, values)
+	assert.Equal(t, []error{errors.New("connection lost")}, errs)
+}
+
+func TestParallelEachChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan int)
+	go func() {
+		for i := 0; i < 20; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	out, err := transformation.ParallelEach(4, transformation.By(func(from interface{}) (interface{}, error) {
+		i := from.(int)
+		time.Sleep(time.Duration(20-i) * time.Millisecond / 10)
+
+		return i * i, nil
+	})).TransformContext(ctx, ch)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	values, errs := collectSequence(t, out)
+	assert.Empty(t, errs)
+	if assert.Len(t, values, 20) {
+		for i, v := range values {
+			assert.Equal(t, i*i, v)
+		}
+	}
+}
+
+func TestEachSequenceBackPressure(t *testing.T) {
+	values := make([]interface{}, 100)
+	for i := range values {
+		values[i] = i
+	}
+	it := &sliceIterator{values: values}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, err := transformation.ParallelEach(2, transformation.ToString).TransformContext(ctx, it)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	seq := out.(transformation.Sequence)
+	assert.Equal(t, "0", <-seq.Values)
+	time.Sleep(20 * time.Millisecond)
+
+	// one value received, two being transformed and one read ahead
+	assert.LessOrEqual(t, int(atomic.LoadInt32(&it.read)), 4)
+}
+
+func TestEachSequenceCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out, err := transformation.Each(transformation.ToString).TransformContext(ctx, ch)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	seq := out.(transformation.Sequence)
+	assert.Equal(t, "0", <-seq.Values)
+	assert.Equal(t, "1", <-seq.Values)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		for range seq.Values {
+		}
+		for range seq.Errors {
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the sequence was not closed after the context was cancelled")
+	}
+}
+
+func TestEachCheckSequence(t *testing.T) {
+	out, err := transformation.Check(reflect.TypeOf(make(chan string)), transformation.Each(transformation.Trim))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(transformation.Sequence{}), out)
+	}
+
+	out, err = transformation.Check(reflect.TypeOf(&sliceIterator{}), transformation.Each(transformation.Trim))
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(transformation.Sequence{}), out)
+	}
+
+	_, err = transformation.Check(reflect.TypeOf(make(chan int)), transformation.Each(transformation.Reverse))
+	assert.EqualError(t, err, "step 1 (Each): step 1 (Reverse): expected string but got int")
+}
@@ -0,0 +1,121 @@
+package transformation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type (
+	// SplitField is a rule which fans the value of a struct field out into several destinations.
+	SplitField struct {
+		from         interface{}
+		fn           SplitFunc
+		to           []interface{}
+		transformers []Transformer
+	}
+
+	// SplitFunc splits a value into one value per destination of a SplitField.
+	SplitFunc func(from interface{}) ([]interface{}, error)
+)
+
+// Split returns a rule which splits the value of the given struct field with fn and copies
+// every resulting value to the destination at the same position.
+func Split(from interface{}, fn SplitFunc, to ...interface{}) *SplitField {
+	return &SplitField{
+		from: from,
+		fn:   fn,
+		to:   to,
+	}
+}
+
+// With sets the transformers applied to the field value before it is split.
+func (s *SplitField) With(transformers ...Transformer) *SplitField {
+	return &SplitField{
+		from:         s.from,
+		fn:           s.fn,
+		to:           s.to,
+		transformers: transformers,
+	}
+}
+
+func (s *SplitField) fieldName(structValue reflect.Value) (string, error) {
+	name, err := fieldName(structValue, s.from, "from")
+	if err != nil {
+		return "", err
+	}
+
+	if s.fn == nil {
+		return "", errors.New("split func is missing")
+	}
+
+	if len(s.to) == 0 {
+		return "", errors.New("split expects at least one destination")
+	}
+
+	for _, to := range s.to {
+		if reflect.ValueOf(to).Kind() != reflect.Ptr {
+			return "", fmt.Errorf("destination expected to be a pointer but got %T", to)
+		}
+	}
+
+	return name, nil
+}
+
+func (s *SplitField) sources() []interface{} {
+	return []interface{}{s.from}
+}
+
+func (s *SplitField) targets() []interface{} {
+	return s.to
+}
+
+func (s *SplitField) dependencies() []interface{} {
+	return nil
+}
+
+func (s *SplitField) pipeline() []Transformer {
+	return s.transformers
+}
+
+func (s *SplitField) rebase(fn func(ptr interface{}) interface{}) Rule {
+	to := make([]interface{}, len(s.to))
+	for i, ptr := range s.to {
+		to[i] = fn(ptr)
+	}
+
+	return &SplitField{
+		from:         fn(s.from),
+		fn:           s.fn,
+		to:           to,
+		transformers: s.transformers,
+	}
+}
+
+func (s *SplitField) apply(e *execution, structPtr reflect.Value) error {
+	v, err := e.transform(s.from, s.transformers)
+	if err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	values, err := s.fn(v)
+	if err != nil {
+		return err
+	}
+
+	if len(values) != len(s.to) {
+		return fmt.Errorf("expected %d values but got %d", len(s.to), len(values))
+	}
+
+	for i, value := range values {
+		if err := copyValue(value, s.to[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
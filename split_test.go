@@ -0,0 +1,63 @@
+package transformation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strings"
+	"testing"
+)
+
+type Author struct {
+	Raw    string
+	First  string
+	Middle *string
+	Last   string
+}
+
+func splitName(from interface{}) ([]interface{}, error) {
+	parts := strings.Fields(from.(string))
+	values := make([]interface{}, len(parts))
+	for i, part := range parts {
+		values[i] = part
+	}
+
+	return values, nil
+}
+
+func TestSplit(t *testing.T) {
+	a := Author{Raw: "  john ronald tolkien "}
+	err := transformation.TransformStructRules(
+		&a,
+		transformation.Split(&a.Raw, splitName, &a.First, &a.Middle, &a.Last).
+			With(transformation.Trim, transformation.UpperCase),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "JOHN", a.First)
+		if assert.NotNil(t, a.Middle) {
+			assert.Equal(t, "RONALD", *a.Middle)
+		}
+		assert.Equal(t, "TOLKIEN", a.Last)
+	}
+}
+
+func TestSplitArity(t *testing.T) {
+	a := Author{Raw: "John Tolkien"}
+	err := transformation.TransformStructRules(
+		&a,
+		transformation.Split(&a.Raw, splitName, &a.First, &a.Middle, &a.Last),
+	)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Raw: expected 3 values but got 2.", err.Error())
+		assert.Zero(t, a.First)
+		assert.Zero(t, a.Last)
+	}
+}
+
+func TestSplitInvalidDestination(t *testing.T) {
+	a := Author{Raw: "John Tolkien"}
+	err := transformation.TransformStructRules(
+		&a,
+		transformation.Split(&a.Raw, splitName, &a.First, a.Last),
+	)
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package transformation
+
+import (
+	"reflect"
+)
+
+type (
+	// stage holds a private copy of a struct and of every other destination written by the
+	// rules, so the originals are only written when the stage is committed.
+	stage struct {
+		orig  reflect.Value
+		clone reflect.Value
+		cells []stagedCell
+	}
+
+	stagedCell struct {
+		orig reflect.Value
+		cell reflect.Value
+	}
+)
+
+// newStage returns a stage for the struct the given pointer points to.
+func newStage(structPtr reflect.Value) *stage {
+	return &stage{
+		orig:  structPtr,
+		clone: cloneStruct(structPtr.Elem()),
+	}
+}
+
+// pointer maps a pointer to the original values to the corresponding pointer to the staged
+// values. Pointers to fields of the struct are mapped to the fields of its clone; any other
+// pointer is mapped to a staged copy of the value it points to.
+func (s *stage) pointer(ptr interface{}) interface{} {
+	pv := reflect.ValueOf(ptr)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() {
+		return ptr
+	}
+
+	if f, ok := locateField(s.orig.Elem(), s.clone.Elem(), pv); ok {
+		return f.Addr().Interface()
+	}
+
+	for _, c := range s.cells {
+		if c.orig.Type() == pv.Type() && c.orig.Pointer() == pv.Pointer() {
+			return c.cell.Interface()
+		}
+	}
+
+	cell := reflect.New(pv.Type().Elem())
+	cell.Elem().Set(pv.Elem())
+	s.cells = append(s.cells, stagedCell{orig: pv, cell: cell})
+
+	return cell.Interface()
+}
+
+// commit copies the staged values to the originals.
+func (s *stage) commit() {
+	commitStruct(s.orig.Elem(), s.clone.Elem())
+	for _, c := range s.cells {
+		c.orig.Elem().Set(c.cell.Elem())
+	}
+}
+
+// cloneStruct returns a pointer to a copy of the given struct. Embedded struct pointers are
+// copied as well, so every field findStructField can reach belongs to the copy.
+func cloneStruct(structValue reflect.Value) reflect.Value {
+	clone := reflect.New(structValue.Type())
+	clone.Elem().Set(structValue)
+
+	for i := 0; i < structValue.NumField(); i++ {
+		f := clone.Elem().Field(i)
+		if isEmbeddedStructPtr(structValue.Type().Field(i)) && !f.IsNil() && f.CanSet() {
+			f.Set(cloneStruct(f.Elem()))
+		}
+	}
+
+	return clone
+}
+
+// commitStruct copies the src struct to dst, writing embedded struct pointers through to the
+// structs dst points to instead of replacing the pointers.
+func commitStruct(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		df := dst.Field(i)
+		sf := src.Field(i)
+		if !isEmbeddedStructPtr(dst.Type().Field(i)) || df.IsNil() || sf.IsNil() || df.Pointer() == sf.Pointer() || !sf.CanSet() {
+			continue
+		}
+
+		commitStruct(df.Elem(), sf.Elem())
+		sf.Set(df)
+	}
+
+	dst.Set(src)
+}
+
+// locateField looks for the field of orig the given pointer points to and returns the
+// corresponding field of clone.
+func locateField(orig, clone reflect.Value, fieldValue reflect.Value) (reflect.Value, bool) {
+	ptr := fieldValue.Pointer()
+	for i := orig.NumField() - 1; i >= 0; i-- {
+		sf := orig.Type().Field(i)
+		if ptr == orig.Field(i).UnsafeAddr() && sf.Type == fieldValue.Elem().Type() {
+			return clone.Field(i), true
+		}
+		if !sf.Anonymous {
+			continue
+		}
+
+		of := orig.Field(i)
+		cf := clone.Field(i)
+		if sf.Type.Kind() == reflect.Ptr {
+			if of.IsNil() || cf.IsNil() || of.Pointer() == cf.Pointer() {
+				continue
+			}
+			of = of.Elem()
+			cf = cf.Elem()
+		}
+		if of.Kind() == reflect.Struct {
+			if f, ok := locateField(of, cf, fieldValue); ok {
+				return f, true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func isEmbeddedStructPtr(sf reflect.StructField) bool {
+	return sf.Anonymous && sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct
+}
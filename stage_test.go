@@ -0,0 +1,68 @@
+package transformation_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+type (
+	Audit struct {
+		CreatedBy string
+	}
+
+	Record struct {
+		*Audit
+		Name  string
+		Email string
+		Slug  string
+	}
+)
+
+func TestTransformStructAtomic(t *testing.T) {
+	r := Record{Audit: &Audit{CreatedBy: " admin "}, Name: " John ", Email: " JOHN@EXAMPLE.COM "}
+	var external string
+	err := transformation.TransformStructWithOptions(
+		&r,
+		[]transformation.Option{transformation.Atomic()},
+		transformation.Field(&r.Name, &r.Name, transformation.Trim),
+		transformation.Field(&r.CreatedBy, &r.CreatedBy, transformation.Trim),
+		transformation.Field(&r.Name, &external, transformation.UpperCase),
+		transformation.Field(&r.Email, &r.Email, transformation.By(func(from interface{}) (interface{}, error) {
+			return nil, errors.New("invalid email")
+		})),
+	)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Email: invalid email.", err.Error())
+		assert.Equal(t, " John ", r.Name)
+		assert.Equal(t, " admin ", r.CreatedBy)
+		assert.Equal(t, " JOHN@EXAMPLE.COM ", r.Email)
+		assert.Zero(t, external)
+	}
+}
+
+func TestTransformStructAtomicCommit(t *testing.T) {
+	audit := &Audit{CreatedBy: " admin "}
+	r := Record{Audit: audit, Name: " John ", Email: " JOHN@EXAMPLE.COM "}
+	var external string
+	err := transformation.TransformStructWithOptions(
+		&r,
+		[]transformation.Option{transformation.Atomic()},
+		transformation.Field(&r.Name, &r.Name, transformation.Trim),
+		transformation.Field(&r.CreatedBy, &r.CreatedBy, transformation.Trim),
+		transformation.Field(&r.Name, &external, transformation.UpperCase),
+		transformation.Field(&r.Email, &r.Email, transformation.Trim, transformation.DownCase),
+		transformation.Computed(&r.Slug, func(r *Record) (interface{}, error) {
+			return r.Name + "-" + r.CreatedBy, nil
+		}, &r.Name, &r.CreatedBy),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "John", r.Name)
+		assert.Equal(t, "john@example.com", r.Email)
+		assert.Equal(t, "John-admin", r.Slug)
+		assert.Equal(t, "JOHN", external)
+		assert.Same(t, audit, r.Audit)
+		assert.Equal(t, "admin", audit.CreatedBy)
+	}
+}
@@ -0,0 +1,524 @@
+package transformation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Formats of the records read and written by TransformStream.
+const (
+	JSONLines StreamFormat = "jsonl"
+	CSV       StreamFormat = "csv"
+)
+
+type (
+	// StreamFormat is the format of a stream of records.
+	StreamFormat string
+
+	// StreamOptions configures TransformStream.
+	StreamOptions struct {
+		// Input is the format of the records read. It defaults to JSONLines.
+		Input StreamFormat
+		// Output is the format of the records written. It defaults to the input format.
+		Output StreamFormat
+		// New returns a pointer to a new struct every record is decoded into. If it is nil,
+		// records are decoded into a map[string]interface{}.
+		New func() interface{}
+		// Rules returns the rules applied to the struct the given pointer points to.
+		Rules func(structPtr interface{}) ([]Rule, error)
+		// RuleSet is applied to every record, unless Rules is set.
+		RuleSet *RuleSet
+		// Options are the options every record is transformed with.
+		Options []Option
+		// Columns are the columns of the CSV output. They default to the columns of the CSV
+		// input, the JSON names of the struct fields or the sorted keys of the first map.
+		Columns []string
+		// Rejects receives the records which could not be decoded or transformed as JSON
+		// Lines of RejectedRecord. If it is nil, TransformStream stops at the first failing record.
+		Rejects io.Writer
+		// DryRun writes a JSON Line of RecordPatch for every record the transformation would
+		// change, instead of the transformed records.
+		DryRun bool
+	}
+
+	// StreamStats counts the records processed by TransformStream. In a dry run, Written is the
+	// number of records which would be changed.
+	StreamStats struct {
+		Read     int `json:"read"`
+		Written  int `json:"written"`
+		Rejected int `json:"rejected"`
+	}
+
+	// RejectedRecord is a record which could not be decoded or transformed.
+	RejectedRecord struct {
+		// Record is the position of the record in the input, starting at 1.
+		Record int `json:"record"`
+		// Data is the record as it was read.
+		Data interface{} `json:"data,omitempty"`
+		// Error is the error of a record which could not be decoded, or transformed at all,
+		// e.g. because a transformer panicked.
+		Error string `json:"error,omitempty"`
+		// Errors are the errors of the fields which could not be decoded or transformed.
+		Errors map[string]string `json:"errors,omitempty"`
+	}
+
+	// RecordPatch is the JSON Patch describing the changes made to a record.
+	RecordPatch struct {
+		// Record is the position of the record in the input, starting at 1.
+		Record int   `json:"record"`
+		Patch  Patch `json:"patch"`
+	}
+
+	// recordDecoder reads records one at a time.
+	recordDecoder interface {
+		// next returns the next record and its raw data. A record which cannot be decoded is
+		// returned with its decoding error, io.EOF is returned at the end of the input.
+		next() (record interface{}, raw interface{}, recordErr error, err error)
+		columns() []string
+	}
+
+	// recordEncoder writes records one at a time.
+	recordEncoder interface {
+		write(record interface{}) error
+		flush() error
+	}
+
+	jsonLinesDecoder struct {
+		r         *bufio.Reader
+		newRecord func() interface{}
+	}
+
+	jsonLinesEncoder struct {
+		enc *json.Encoder
+	}
+
+	csvDecoder struct {
+		r         *csv.Reader
+		newRecord func() interface{}
+		header    []string
+	}
+
+	csvEncoder struct {
+		w       *csv.Writer
+		columns func(record interface{}) []string
+		header  []string
+		row     []string
+	}
+)
+
+// TransformStream reads the records of r one at a time, transforms them and writes them to w,
+// so memory use does not depend on the size of the input. It returns the number of records
+// read, written and rejected.
+func TransformStream(r io.Reader, w io.Writer, opts StreamOptions) (StreamStats, error) {
+	var stats StreamStats
+
+	if opts.Input == "" {
+		opts.Input = JSONLines
+	}
+	if opts.Output == "" {
+		opts.Output = opts.Input
+	}
+
+	newRecord := opts.New
+	if newRecord == nil {
+		newRecord = func() interface{} {
+			return &map[string]interface{}{}
+		}
+	}
+
+	dec, err := newRecordDecoder(r, opts.Input, newRecord)
+	if err != nil {
+		return stats, err
+	}
+
+	output := opts.Output
+	if opts.DryRun {
+		output = JSONLines
+	}
+
+	enc, err := newRecordEncoder(w, output, func(record interface{}) []string {
+		if len(opts.Columns) > 0 {
+			return opts.Columns
+		}
+		if columns := dec.columns(); columns != nil {
+			return columns
+		}
+
+		return recordColumns(record)
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	var rejects *json.Encoder
+	if opts.Rejects != nil {
+		rejects = json.NewEncoder(opts.Rejects)
+	}
+
+	for {
+		record, raw, recordErr, err := dec.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.Read++
+
+		var before interface{}
+		if recordErr == nil && opts.DryRun {
+			before, recordErr = toJSONDocument(record)
+		}
+
+		if recordErr == nil {
+			recordErr = transformRecord(record, opts)
+		}
+
+		if recordErr != nil {
+			if rejects == nil {
+				if err := enc.flush(); err != nil {
+					return stats, err
+				}
+
+				return stats, fmt.Errorf("record %d: %v", stats.Read, recordErr)
+			}
+
+			if err := rejects.Encode(newRejectedRecord(stats.Read, raw, recordErr)); err != nil {
+				return stats, err
+			}
+			stats.Rejected++
+			continue
+		}
+
+		if opts.DryRun {
+			after, err := toJSONDocument(record)
+			if err != nil {
+				return stats, fmt.Errorf("record %d: %v", stats.Read, err)
+			}
+
+			patch := diffDocuments(nil, "", before, after)
+			if len(patch) == 0 {
+				continue
+			}
+			record = RecordPatch{Record: stats.Read, Patch: patch}
+		}
+
+		if err := enc.write(record); err != nil {
+			return stats, err
+		}
+		stats.Written++
+	}
+
+	return stats, enc.flush()
+}
+
+// transformRecord applies the rules to the record. A panicking transformer fails the record
+// instead of the stream.
+func transformRecord(record interface{}, opts StreamOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("transformation panicked: %v", r)
+		}
+	}()
+
+	if m, ok := record.(*map[string]interface{}); ok {
+		if opts.RuleSet == nil {
+			return nil
+		}
+
+		return opts.RuleSet.TransformMapWithOptions(*m, opts.Options)
+	}
+
+	var rules []Rule
+	switch {
+	case opts.Rules != nil:
+		rules, err = opts.Rules(record)
+	case opts.RuleSet != nil:
+		rules, err = opts.RuleSet.Rules(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	return TransformStructWithOptions(record, opts.Options, rules...)
+}
+
+func newRejectedRecord(record int, raw interface{}, err error) RejectedRecord {
+	reject := RejectedRecord{Record: record, Data: raw}
+	if errs, ok := err.(Errors); ok {
+		reject.Errors = make(map[string]string, len(errs))
+		for field, err := range errs {
+			reject.Errors[field] = err.Error()
+		}
+	} else {
+		reject.Error = err.Error()
+	}
+
+	return reject
+}
+
+func newRecordDecoder(r io.Reader, format StreamFormat, newRecord func() interface{}) (recordDecoder, error) {
+	switch format {
+	case JSONLines:
+		return &jsonLinesDecoder{r: bufio.NewReader(r), newRecord: newRecord}, nil
+	case CSV:
+		cr := csv.NewReader(r)
+		cr.ReuseRecord = true
+
+		return &csvDecoder{r: cr, newRecord: newRecord}, nil
+	default:
+		return nil, fmt.Errorf("unknown stream format %q", format)
+	}
+}
+
+func newRecordEncoder(w io.Writer, format StreamFormat, columns func(record interface{}) []string) (recordEncoder, error) {
+	switch format {
+	case JSONLines:
+		return &jsonLinesEncoder{enc: json.NewEncoder(w)}, nil
+	case CSV:
+		return &csvEncoder{w: csv.NewWriter(w), columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("unknown stream format %q", format)
+	}
+}
+
+func (d *jsonLinesDecoder) next() (interface{}, interface{}, error, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, nil, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err == io.EOF {
+				return nil, nil, nil, io.EOF
+			}
+			continue
+		}
+
+		var raw interface{} = json.RawMessage(line)
+		if !json.Valid(line) {
+			raw = string(line)
+		}
+
+		record := d.newRecord()
+		if decodeErr := json.Unmarshal(line, record); decodeErr != nil {
+			return nil, raw, decodeErr, nil
+		}
+
+		return record, raw, nil, nil
+	}
+}
+
+func (d *jsonLinesDecoder) columns() []string {
+	return nil
+}
+
+func (e *jsonLinesEncoder) write(record interface{}) error {
+	return e.enc.Encode(record)
+}
+
+func (e *jsonLinesEncoder) flush() error {
+	return nil
+}
+
+func (d *csvDecoder) next() (interface{}, interface{}, error, error) {
+	if d.header == nil {
+		header, err := d.r.Read()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		d.header = append([]string(nil), header...)
+	}
+
+	row, err := d.r.Read()
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); ok {
+			return nil, nil, err, nil
+		}
+
+		return nil, nil, nil, err
+	}
+
+	raw := make(map[string]string, len(d.header))
+	for i, column := range d.header {
+		raw[column] = row[i]
+	}
+
+	record := d.newRecord()
+	if m, ok := record.(*map[string]interface{}); ok {
+		for column, v := range raw {
+			(*m)[column] = v
+		}
+
+		return record, raw, nil, nil
+	}
+
+	structValue := reflect.ValueOf(record).Elem()
+	errs := Errors{}
+	for i, column := range d.header {
+		f, ok := lookupField(structValue, FieldRules{Field: column})
+		if !ok || !f.CanSet() {
+			continue
+		}
+
+		if err := setFromString(f, row[i]); err != nil {
+			errs[column] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, raw, errs, nil
+	}
+
+	return record, raw, nil, nil
+}
+
+func (d *csvDecoder) columns() []string {
+	return d.header
+}
+
+func (e *csvEncoder) write(record interface{}) error {
+	if e.header == nil {
+		e.header = e.columns(record)
+		e.row = make([]string, len(e.header))
+		if err := e.w.Write(e.header); err != nil {
+			return err
+		}
+	}
+
+	value := reflect.ValueOf(record).Elem()
+	for i, column := range e.header {
+		var v interface{}
+		if m, ok := record.(*map[string]interface{}); ok {
+			v = (*m)[column]
+		} else if f, ok := lookupField(value, FieldRules{Field: column}); ok {
+			v = f.Interface()
+		}
+
+		s, err := formatString(v)
+		if err != nil {
+			return fmt.Errorf("%s: %v", column, err)
+		}
+		e.row[i] = s
+	}
+
+	return e.w.Write(e.row)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+// recordColumns returns the JSON names of the fields of a struct record or the sorted keys of
+// a map record.
+func recordColumns(record interface{}) []string {
+	if m, ok := record.(*map[string]interface{}); ok {
+		return sortedKeys(*m)
+	}
+
+	var columns []string
+	for _, sf := range jsonFields(reflect.TypeOf(record).Elem()) {
+		if name, ok := jsonFieldName(sf); ok {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
+
+// setFromString sets the field to the value parsed from the string. Empty strings leave
+// fields which are not strings unset.
+func setFromString(f reflect.Value, s string) error {
+	if s == "" && f.Kind() != reflect.String {
+		return nil
+	}
+
+	if f.Kind() == reflect.Ptr {
+		v := reflect.New(f.Type().Elem())
+		if err := setFromString(v.Elem(), s); err != nil {
+			return err
+		}
+		f.Set(v)
+
+		return nil
+	}
+
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Array:
+		return json.Unmarshal([]byte(s), f.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+
+	return nil
+}
+
+// formatString formats a value as a CSV field. Slices, maps and structs are formatted as JSON.
+func formatString(v interface{}) (string, error) {
+	v, isNil := indirect(v)
+	if isNil {
+		return "", nil
+	}
+
+	switch tv := v.(type) {
+	case string:
+		return tv, nil
+	case time.Time:
+		return tv.Format(time.RFC3339Nano), nil
+	case encoding.TextMarshaler:
+		b, err := tv.MarshalText()
+
+		return string(b), err
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Array:
+		b, err := json.Marshal(v)
+
+		return string(b), err
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,190 @@
+package transformation_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"strings"
+	"testing"
+)
+
+type Subscriber struct {
+	Email string  `json:"email" transform:"trim|down_case"`
+	Name  string  `json:"name" transform:"trim|upper_case"`
+	Age   int     `json:"age"`
+	Plan  *string `json:"plan" transform:"default(\"free\")"`
+}
+
+func newSubscriber() interface{} {
+	return &Subscriber{}
+}
+
+func subscriberRules(t *testing.T) func(structPtr interface{}) ([]transformation.Rule, error) {
+	rs, err := transformation.RuleSetFromTags(Subscriber{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return rs.Rules
+}
+
+func TestTransformStreamJSONLines(t *testing.T) {
+	in := strings.NewReader(`{"email":" John@Example.COM ","name":" john ","age":30}
+
+{"email":"jane@example.com","name":"jane","age":25,"plan":"pro"}
+`)
+	var out bytes.Buffer
+
+	stats, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		New:   newSubscriber,
+		Rules: subscriberRules(t),
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, transformation.StreamStats{Read: 2, Written: 2}, stats)
+		assert.Equal(t, `{"email":"john@example.com","name":"JOHN","age":30,"plan":"free"}
+{"email":"jane@example.com","name":"JANE","age":25,"plan":"pro"}
+`, out.String())
+	}
+}
+
+func TestTransformStreamCSVToJSONLines(t *testing.T) {
+	in := strings.NewReader("name,email,age\n john ,JOHN@EXAMPLE.COM,30\njane,jane@example.com,\nbob,bob@example.com,old\n")
+	var out bytes.Buffer
+
+	stats, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		Input:  transformation.CSV,
+		Output: transformation.JSONLines,
+		New:    newSubscriber,
+		Rules:  subscriberRules(t),
+	})
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.EqualError(t, err, `record 3: age: strconv.ParseInt: parsing "old": invalid syntax.`)
+	assert.Equal(t, transformation.StreamStats{Read: 3, Written: 2}, stats)
+	assert.Equal(t, `{"email":"john@example.com","name":"JOHN","age":30,"plan":"free"}
+{"email":"jane@example.com","name":"JANE","age":0,"plan":"free"}
+`, out.String())
+}
+
+func TestTransformStreamRejects(t *testing.T) {
+	in := strings.NewReader(`{"email":" a@example.com ","name":"a","age":1}
+{"email":"b@example.com","age":"two"}
+{"email":"c@example.com","name":"c","age":3,"plan":"pro"}
+not json
+`)
+	var out, rejects bytes.Buffer
+
+	stats, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		Output:  transformation.CSV,
+		New:     newSubscriber,
+		Rules:   subscriberRules(t),
+		Rejects: &rejects,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, transformation.StreamStats{Read: 4, Written: 2, Rejected: 2}, stats)
+	assert.Equal(t, "email,name,age,plan\na@example.com,A,1,free\nc@example.com,C,3,pro\n", out.String())
+	assert.Equal(t, `{"record":2,"data":{"email":"b@example.com","age":"two"},"error":"json: cannot unmarshal string into Go struct field Subscriber.age of type int"}
+{"record":4,"data":"not json","error":"invalid character 'o' in literal null (expecting 'u')"}
+`, rejects.String())
+}
+
+func TestTransformStreamMaps(t *testing.T) {
+	rs, err := transformation.NewRuleSet("subscriber",
+		transformation.FieldRules{Field: "Email", JSONName: "email", Pipeline: "trim|down_case"},
+		transformation.FieldRules{Field: "Plan", JSONName: "plan", Pipeline: `default("free")`},
+		transformation.FieldRules{Field: "Since", JSONName: "since", Pipeline: `parse_time("2006-01-02")`},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	in := strings.NewReader(`{"email":" A@Example.com ","since":"2020-01-02"}
+{"email":"b@example.com","since":"yesterday"}
+`)
+	var out, rejects bytes.Buffer
+
+	stats, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		Output:  transformation.CSV,
+		RuleSet: rs,
+		Rejects: &rejects,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, transformation.StreamStats{Read: 2, Written: 1, Rejected: 1}, stats)
+	assert.Equal(t, "email,plan,since\na@example.com,free,2020-01-02T00:00:00Z\n", out.String())
+	assert.Contains(t, rejects.String(), `{"record":2,"data":{"email":"b@example.com","since":"yesterday"},"errors":{"Since":`)
+}
+
+func TestTransformStreamPanics(t *testing.T) {
+	rs, err := transformation.NewRuleSet("payment",
+		transformation.FieldRules{Field: "Amount", JSONName: "amount", Pipeline: "money(100)"},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	in := strings.NewReader("id,amount\n1,250\n")
+	var out, rejects bytes.Buffer
+
+	stats, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		Input:   transformation.CSV,
+		RuleSet: rs,
+		Rejects: &rejects,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, transformation.StreamStats{Read: 1, Rejected: 1}, stats)
+		assert.Contains(t, rejects.String(), `{"record":1,"data":{"amount":"250","id":"1"},"error":"transformation panicked: `)
+	}
+
+	_, err = transformation.TransformStream(strings.NewReader("id,amount\n1,250\n"), &out, transformation.StreamOptions{
+		Input:   transformation.CSV,
+		RuleSet: rs,
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "record 1: transformation panicked: ")
+	}
+}
+
+func TestTransformStreamColumns(t *testing.T) {
+	in := strings.NewReader("email,name,age,plan\nA@EXAMPLE.COM,a,1,\n")
+	var out bytes.Buffer
+
+	_, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		Input:   transformation.CSV,
+		New:     newSubscriber,
+		Rules:   subscriberRules(t),
+		Columns: []string{"name", "plan"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name,plan\nA,free\n", out.String())
+	}
+
+	_, err = transformation.TransformStream(in, &out, transformation.StreamOptions{Input: "xml"})
+	assert.EqualError(t, err, `unknown stream format "xml"`)
+}
+
+func TestTransformStreamDryRun(t *testing.T) {
+	in := strings.NewReader(`{"email":" A@Example.com ","name":"A","age":1,"plan":"pro"}
+{"email":"b@example.com","name":"B","age":2,"plan":"pro"}
+`)
+	var out bytes.Buffer
+
+	stats, err := transformation.TransformStream(in, &out, transformation.StreamOptions{
+		Output: transformation.CSV,
+		New:    newSubscriber,
+		Rules:  subscriberRules(t),
+		DryRun: true,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, transformation.StreamStats{Read: 2, Written: 1}, stats)
+		assert.Equal(t, `{"record":1,"patch":[{"op":"replace","path":"/email","value":"a@example.com"}]}
+`, out.String())
+	}
+}
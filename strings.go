@@ -0,0 +1,214 @@
+package transformation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+type (
+	// splitTransformer splits a string into a []string.
+	splitTransformer struct {
+		name   string
+		params map[string]interface{}
+		split  func(s string) ([]string, error)
+	}
+
+	// joinTransformer joins the elements of a slice or an array into a string.
+	joinTransformer struct {
+		sep string
+		csv bool
+	}
+)
+
+var stringSliceType = reflect.TypeOf([]string(nil))
+
+// SplitString returns a transformer which splits a string around every separator into a
+// []string, e.g. "a, b ,c" becomes []string{"a", " b ", "c"} with ",". Values which are not
+// strings are formatted first. An empty string becomes an empty slice and nil stays nil.
+func SplitString(sep string) *splitTransformer {
+	return &splitTransformer{
+		name:   "SplitString",
+		params: map[string]interface{}{"separator": sep},
+		split: func(s string) ([]string, error) {
+			return strings.Split(s, sep), nil
+		},
+	}
+}
+
+// SplitRegexp returns a transformer like SplitString which splits around every match of the
+// regular expression, e.g. `\s*,\s*`.
+func SplitRegexp(re *regexp.Regexp) *splitTransformer {
+	return &splitTransformer{
+		name:   "SplitRegexp",
+		params: map[string]interface{}{"pattern": re.String()},
+		split: func(s string) ([]string, error) {
+			return re.Split(s, -1), nil
+		},
+	}
+}
+
+// SplitCSV returns a transformer like SplitString which splits a single CSV record around the
+// separator, so quoted values may contain it, e.g. `a, "b, c"` becomes []string{"a", "b, c"}
+// with ','. Spaces before a value are dropped.
+func SplitCSV(sep rune) *splitTransformer {
+	return &splitTransformer{
+		name:   "SplitCSV",
+		params: map[string]interface{}{"separator": string(sep)},
+		split: func(s string) ([]string, error) {
+			r := csv.NewReader(strings.NewReader(s))
+			r.Comma = sep
+			r.FieldsPerRecord = -1
+			r.TrimLeadingSpace = true
+
+			records, err := r.ReadAll()
+			if err != nil {
+				return nil, err
+			}
+
+			if len(records) != 1 {
+				return nil, fmt.Errorf("expected a single record but got %d", len(records))
+			}
+
+			return records[0], nil
+		},
+	}
+}
+
+// Join returns a transformer which joins the elements of a slice or an array into a string
+// with the separator. Elements which are not strings are formatted and nil elements are skipped.
+func Join(sep string) joinTransformer {
+	return joinTransformer{sep: sep}
+}
+
+// JoinCSV returns a transformer like Join which writes the elements as a single CSV record, so
+// the elements containing the separator or quotes are quoted.
+func JoinCSV(sep rune) joinTransformer {
+	return joinTransformer{sep: string(sep), csv: true}
+}
+
+func (t *splitTransformer) Transform(from interface{}) (interface{}, error) {
+	if _, isNil := indirect(from); isNil {
+		return nil, nil
+	}
+
+	v, err := ToString.Transform(from)
+	if err != nil {
+		return nil, err
+	}
+
+	s := v.(string)
+	if s == "" {
+		return []string{}, nil
+	}
+
+	return t.split(s)
+}
+
+func (t *splitTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	return stringSliceType, nil
+}
+
+func (t *splitTransformer) Name() string {
+	return t.name
+}
+
+func (t *splitTransformer) Description() string {
+	return "Splits a string into a slice of strings."
+}
+
+func (t *splitTransformer) Params() map[string]interface{} {
+	return t.params
+}
+
+func (t *splitTransformer) ErrorConditions() []string {
+	if t.name != "SplitCSV" {
+		return nil
+	}
+
+	return []string{"Fails when the value is not a single valid CSV record."}
+}
+
+func (t joinTransformer) Transform(from interface{}) (interface{}, error) {
+	from, isNil := indirect(from)
+	if isNil {
+		return nil, nil
+	}
+
+	fromValue := reflect.ValueOf(from)
+	if fromValue.Kind() != reflect.Slice && fromValue.Kind() != reflect.Array {
+		return nil, fmt.Errorf("must be a slice or an array but got %T", from)
+	}
+
+	values := make([]string, 0, fromValue.Len())
+	for i := 0; i < fromValue.Len(); i++ {
+		v, isNil := indirect(fromValue.Index(i).Interface())
+		if isNil {
+			continue
+		}
+
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		} else {
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+
+	if !t.csv {
+		return strings.Join(values, t.sep), nil
+	}
+
+	return joinCSV(values, []rune(t.sep)[0])
+}
+
+func (t joinTransformer) CheckType(in reflect.Type) (reflect.Type, error) {
+	if in == nil {
+		return stringType, nil
+	}
+
+	switch indirectType(in).Kind() {
+	case reflect.Slice, reflect.Array:
+		return stringType, nil
+	default:
+		return nil, fmt.Errorf("expected a slice or an array but got %s", in)
+	}
+}
+
+func (t joinTransformer) Name() string {
+	if t.csv {
+		return "JoinCSV"
+	}
+
+	return "Join"
+}
+
+func (t joinTransformer) Description() string {
+	return "Joins the elements of a slice or an array into a string."
+}
+
+func (t joinTransformer) Params() map[string]interface{} {
+	return map[string]interface{}{"separator": t.sep}
+}
+
+func (t joinTransformer) ErrorConditions() []string {
+	return []string{"Fails when the value is not a slice or an array."}
+}
+
+func joinCSV(values []string, sep rune) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = sep
+	if err := w.Write(values); err != nil {
+		return "", err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,115 @@
+package transformation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSplitString(t *testing.T) {
+	var tags []string
+	err := transformation.Transform("a, b ,c, a", &tags,
+		transformation.SplitString(","), transformation.Each(transformation.Trim), transformation.Unique())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b", "c"}, tags)
+	}
+
+	var ptrs []*string
+	err = transformation.Transform("x|y", &ptrs, transformation.SplitString("|"))
+	if assert.NoError(t, err) && assert.Len(t, ptrs, 2) {
+		assert.Equal(t, "x", *ptrs[0])
+		assert.Equal(t, "y", *ptrs[1])
+	}
+
+	out, err := transformation.SplitString(",").Transform("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{}, out)
+	}
+
+	out, err = transformation.SplitString(",").Transform((*string)(nil))
+	if assert.NoError(t, err) {
+		assert.Nil(t, out)
+	}
+}
+
+func TestSplitRegexp(t *testing.T) {
+	out, err := transformation.SplitRegexp(regexp.MustCompile(`\s*[,;]\s*`)).Transform("a , b;c")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b", "c"}, out)
+	}
+}
+
+func TestSplitCSV(t *testing.T) {
+	out, err := transformation.SplitCSV(',').Transform(`a, "b, c",d`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b, c", "d"}, out)
+	}
+
+	_, err = transformation.SplitCSV(',').Transform(`a,"b`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `extraneous or missing " in quoted-field`)
+	}
+
+	_, err = transformation.SplitCSV(',').Transform("a\nb")
+	assert.EqualError(t, err, "expected a single record but got 2")
+}
+
+func TestJoin(t *testing.T) {
+	b := "b"
+	out, err := transformation.Join(", ").Transform([]*string{nil, &b, &b})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "b, b", out)
+	}
+
+	out, err = transformation.Join("-").Transform([2]int{1, 2})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1-2", out)
+	}
+
+	out, err = transformation.JoinCSV(',').Transform([]string{"a", "b, c", `say "hi"`})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `a,"b, c","say ""hi"""`, out)
+
+		back, err := transformation.SplitCSV(',').Transform(out)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"a", "b, c", `say "hi"`}, back)
+		}
+	}
+
+	_, err = transformation.Join(",").Transform("a")
+	assert.EqualError(t, err, "must be a slice or an array but got string")
+}
+
+func TestSplitJoinPipeline(t *testing.T) {
+	ts, err := transformation.ParsePipeline(`split_csv|each(trim|upper_case)|sort|join_csv(";")`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var to string
+	err = transformation.Transform(`b, "a;c"`, &to, ts...)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `"A;C";B`, to)
+	}
+
+	out, err := transformation.Check(reflect.TypeOf(""), ts...)
+	if assert.NoError(t, err) {
+		assert.Equal(t, reflect.TypeOf(""), out)
+	}
+
+	ts, err = transformation.ParsePipeline(`split_regexp("\\s+")|join(",")`)
+	if assert.NoError(t, err) {
+		err = transformation.Transform("a  b\tc", &to, ts...)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "a,b,c", to)
+		}
+	}
+
+	_, err = transformation.ParsePipeline(`split_csv(";;")`)
+	assert.EqualError(t, err, "split_csv: expects a single character separator")
+
+	_, err = transformation.Check(reflect.TypeOf(0), transformation.Join(","))
+	assert.EqualError(t, err, "step 1 (Join): expected a slice or an array but got int")
+}
@@ -0,0 +1,218 @@
+package transformation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	traceStruct   = "struct"
+	tracePipeline = "pipeline"
+	traceField    = "field"
+	traceStep     = "step"
+	traceElement  = "element"
+	traceBranch   = "branch"
+)
+
+// TraceNode is a node of the tree recorded by Explain and WithTrace. Struct and pipeline
+// nodes contain field or step nodes, field nodes contain the steps of their pipeline and
+// the steps of transformers such as Each or Default contain their elements or branches.
+type TraceNode struct {
+	// Kind is one of struct, pipeline, field, step, element or branch.
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Input    interface{}   `json:"input"`
+	Output   interface{}   `json:"output"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Children []*TraceNode  `json:"children,omitempty"`
+
+	mu    sync.Mutex
+	start time.Time
+}
+
+// Explain applies the transformers to the value and returns the trace of every step.
+// The result of the transformation is the output of the returned node.
+func Explain(from interface{}, transformers ...Transformer) *TraceNode {
+	root := newTraceNode(tracePipeline, "", from)
+	e := newExecution(context.Background(), nil)
+	e.trace = root
+
+	to, err := e.transform(from, transformers)
+	root.finish(to, err)
+
+	return root
+}
+
+// WithTrace makes TransformStructWithOptions record the trace of every rule into the given node.
+func WithTrace(trace *TraceNode) Option {
+	return func(o *options) {
+		o.trace = trace
+	}
+}
+
+// String returns the trace as a text tree.
+func (n *TraceNode) String() string {
+	var sb strings.Builder
+	_, _ = n.WriteTo(&sb)
+
+	return sb.String()
+}
+
+// WriteTo writes the trace as a text tree.
+func (n *TraceNode) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	sb.WriteString(n.line())
+	sb.WriteString("\n")
+	n.writeChildren(&sb, "")
+
+	written, err := io.WriteString(w, sb.String())
+
+	return int64(written), err
+}
+
+func (n *TraceNode) writeChildren(sb *strings.Builder, indent string) {
+	for i, child := range n.Children {
+		branch, next := "├─ ", "│  "
+		if i == len(n.Children)-1 {
+			branch, next = "└─ ", "   "
+		}
+
+		sb.WriteString(indent + branch + child.line() + "\n")
+		child.writeChildren(sb, indent+next)
+	}
+}
+
+func (n *TraceNode) line() string {
+	var sb strings.Builder
+	sb.WriteString(n.Kind)
+	if n.Name != "" {
+		sb.WriteString(" " + n.Name)
+	}
+
+	fmt.Fprintf(&sb, ": %s -> ", formatTraceValue(n.Input))
+	if n.Error != "" {
+		sb.WriteString("error: " + n.Error)
+	} else {
+		sb.WriteString(formatTraceValue(n.Output))
+	}
+	fmt.Fprintf(&sb, " (%s)", n.Duration)
+
+	return sb.String()
+}
+
+func formatTraceValue(v interface{}) string {
+	v, isNil := indirect(v)
+	if isNil {
+		return "nil"
+	}
+
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+func newTraceNode(kind, name string, input interface{}) *TraceNode {
+	return &TraceNode{
+		Kind:  kind,
+		Name:  name,
+		Input: input,
+		start: time.Now(),
+	}
+}
+
+// finish records the result of the traced operation. It does nothing on a nil node.
+func (n *TraceNode) finish(output interface{}, err error) {
+	if n == nil {
+		return
+	}
+
+	n.Duration = time.Since(n.start)
+	n.Output = output
+	if err != nil {
+		n.Error = err.Error()
+	}
+}
+
+func (n *TraceNode) addChild(child *TraceNode) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.Children = append(n.Children, child)
+}
+
+// sortChildren sorts the children of the node found in nodes by the order of their index.
+// The other children are kept first.
+func (n *TraceNode) sortChildren(order []int, nodes []*TraceNode) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sorted := make(map[*TraceNode]bool, len(nodes))
+	for _, node := range nodes {
+		if node != nil {
+			sorted[node] = true
+		}
+	}
+
+	children := make([]*TraceNode, 0, len(n.Children))
+	for _, child := range n.Children {
+		if !sorted[child] {
+			children = append(children, child)
+		}
+	}
+
+	for _, i := range order {
+		if nodes[i] != nil {
+			children = append(children, nodes[i])
+		}
+	}
+
+	n.Children = children
+}
+
+// startTrace adds a node to the trace of the execution and returns a copy of the execution
+// recording into the new node. If the execution is not traced, it returns the execution and
+// a nil node.
+func (e *execution) startTrace(kind, name string, input interface{}) (*execution, *TraceNode) {
+	if e.trace == nil {
+		return e, nil
+	}
+
+	node := newTraceNode(kind, name, input)
+	e.trace.addChild(node)
+
+	c := *e
+	c.trace = node
+
+	return &c, node
+}
+
+// traceValue returns the value the given pointers point to; a slice of values is returned
+// for several pointers.
+func traceValue(ptrs []interface{}) interface{} {
+	switch len(ptrs) {
+	case 0:
+		return nil
+	case 1:
+		return snapshotValue(ptrs[0])
+	}
+
+	values := make([]interface{}, len(ptrs))
+	for i, ptr := range ptrs {
+		values[i] = snapshotValue(ptr)
+	}
+
+	return values
+}
+
+func structName(structPtr reflect.Value) string {
+	return structPtr.Type().Elem().Name()
+}
@@ -0,0 +1,78 @@
+package transformation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vcraescu/go-transformation"
+	"testing"
+)
+
+func resetDurations(n *transformation.TraceNode) {
+	n.Duration = 0
+	for _, child := range n.Children {
+		resetDurations(child)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	trace := transformation.Explain(
+		[]*string{nil},
+		transformation.Each(transformation.Default("n/a"), transformation.UpperCase),
+	)
+	resetDurations(trace)
+
+	assert.Equal(t, []string{"N/A"}, trace.Output)
+	assert.Equal(t, `pipeline: [<nil>] -> [N/A] (0s)
+└─ step Each: [<nil>] -> [N/A] (0s)
+   └─ element 0: nil -> "N/A" (0s)
+      ├─ step Default: nil -> "n/a" (0s)
+      │  └─ branch default: nil -> "n/a" (0s)
+      └─ step UpperCase: "n/a" -> "N/A" (0s)
+`, trace.String())
+
+	b, err := json.Marshal(trace.Children[0].Children[0])
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"kind": "element", "name": "0", "input": null, "output": "N/A", "duration": 0,
+			"children": [
+				{
+					"kind": "step", "name": "Default", "input": null, "output": "n/a", "duration": 0,
+					"children": [{"kind": "branch", "name": "default", "input": null, "output": "n/a", "duration": 0}]
+				},
+				{"kind": "step", "name": "UpperCase", "input": "n/a", "output": "N/A", "duration": 0}
+			]
+		}`, string(b))
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	from := Person{FirstName: " John ", Addresses: []string{"a"}}
+	fail := transformation.By(func(from interface{}) (interface{}, error) {
+		return nil, errors.New("invalid")
+	})
+
+	var trace transformation.TraceNode
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{transformation.WithTrace(&trace)},
+		transformation.Field(&from.FirstName, &from.FirstName, transformation.Trim),
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.Try(fail).Or(transformation.Each(transformation.UpperCase))),
+	)
+	resetDurations(&trace)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `struct Person: nil -> nil (0s)
+├─ field FirstName: " John " -> "John" (0s)
+│  └─ step Trim: " John " -> "John" (0s)
+└─ field Addresses: [a] -> [A] (0s)
+   └─ step Try: [a] -> [A] (0s)
+      ├─ branch 0: [a] -> error: invalid (0s)
+      │  └─ step By: [a] -> error: invalid (0s)
+      └─ branch 1: [a] -> [A] (0s)
+         └─ step Each: [a] -> [A] (0s)
+            └─ element 0: "a" -> "A" (0s)
+               └─ step UpperCase: "a" -> "A" (0s)
+`, trace.String())
+	}
+}
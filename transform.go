@@ -1,8 +1,11 @@
 package transformation
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 type (
@@ -12,6 +15,26 @@ type (
 		transformers []Transformer
 	}
 
+	// Rule is a transformation rule applied by TransformStructRules.
+	Rule interface {
+		// fieldName validates the rule against the struct being transformed and returns
+		// the name of the field the rule errors are reported under.
+		fieldName(structValue reflect.Value) (string, error)
+		// sources returns the pointers the rule reads from.
+		sources() []interface{}
+		// targets returns the pointers the rule writes to.
+		targets() []interface{}
+		// dependencies returns the pointers to the struct fields which must be transformed
+		// before the rule is applied.
+		dependencies() []interface{}
+		// apply applies the rule to the struct the given pointer points to.
+		apply(e *execution, structPtr reflect.Value) error
+		// pipeline returns the transformers the rule applies.
+		pipeline() []Transformer
+		// rebase returns a copy of the rule with every pointer replaced by the result of fn.
+		rebase(fn func(ptr interface{}) interface{}) Rule
+	}
+
 	Transformer interface {
 		Transform(from interface{}) (interface{}, error)
 	}
@@ -21,9 +44,30 @@ type (
 	}
 
 	TransformFunc func(from interface{}) (interface{}, error)
+
+	coalesced struct {
+		fields []interface{}
+	}
 )
 
 func TransformStruct(from interface{}, fields ...*FieldTransformer) error {
+	rules := make([]Rule, len(fields))
+	for i, f := range fields {
+		rules[i] = f
+	}
+
+	return TransformStructRules(from, rules...)
+}
+
+// TransformStructRules is like TransformStruct but applies any rule, e.g. Computed or Split
+// rules and the rules of a RuleSet, along with the fields.
+func TransformStructRules(from interface{}, rules ...Rule) error {
+	return TransformStructWithOptions(from, nil, rules...)
+}
+
+// TransformStructWithOptions is like TransformStructRules but its behaviour can be changed with
+// the given options.
+func TransformStructWithOptions(from interface{}, opts []Option, rules ...Rule) error {
 	value := reflect.ValueOf(from)
 	if value.Kind() != reflect.Ptr || (!value.IsNil() && value.Elem().Kind() != reflect.Struct) {
 		return fmt.Errorf("must be a pointer to a struct but got %T", from)
@@ -32,91 +76,235 @@ func TransformStruct(from interface{}, fields ...*FieldTransformer) error {
 	if value.IsNil() {
 		return nil
 	}
-	value = value.Elem()
 
-	errs := Errors{}
+	o := newOptions(opts)
 
-	for _, field := range fields {
-		fv := reflect.ValueOf(field.from)
-		if fv.Kind() != reflect.Ptr {
-			return fmt.Errorf("from field expected to be a pointer but got %T", field.from)
+	plan, err := planRules(value.Elem(), rules)
+	if err != nil {
+		return err
+	}
+
+	target := value
+	var st *stage
+	if o.atomic {
+		st = newStage(value)
+		target = st.clone
+		plan = plan.rebase(st.pointer)
+	}
+
+	var rec *changeRecorder
+	if o.changes != nil {
+		rec = &changeRecorder{}
+	}
+
+	start := time.Now()
+	e := newExecution(o.ctx, o.interceptors)
+	if o.metrics != nil {
+		e.metrics = o.metrics
+	}
+	if o.trace != nil {
+		*o.trace = TraceNode{Kind: traceStruct, Name: structName(value), start: time.Now()}
+		e.trace = o.trace
+	}
+
+	err = plan.run(e, target, rec, o.concurrency)
+	o.trace.finish(nil, err)
+	e.observe(start, err)
+	if err == nil && st != nil {
+		st.commit()
+	}
+
+	if rec != nil {
+		*o.changes = rec.changes
+		if err != nil && st != nil {
+			*o.changes = nil
 		}
-		ft := findStructField(value, fv)
-		if ft == nil {
-			return fmt.Errorf("from field not found")
+	}
+
+	return err
+}
+
+// TransformedCopy applies the rules to a deep copy of the struct the given pointer points to
+// and returns a pointer to the copy, leaving the original untouched. Rule pointers to fields
+// of the original struct are resolved to the corresponding fields of the copy.
+func TransformedCopy(from interface{}, rules ...Rule) (interface{}, error) {
+	value := reflect.ValueOf(from)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must be a non-nil pointer to a struct but got %T", from)
+	}
+
+	plan, err := planRules(value.Elem(), rules)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := deepCopy(value)
+	plan = plan.rebase(func(ptr interface{}) interface{} {
+		pv := reflect.ValueOf(ptr)
+		if pv.Kind() != reflect.Ptr || pv.IsNil() {
+			return ptr
 		}
 
-		if err := Transform(field.from, field.to, field.transformers...); err != nil {
-			errs[ft.Name] = err
+		if f, ok := locateField(value.Elem(), clone.Elem(), pv); ok {
+			return f.Addr().Interface()
 		}
+
+		return ptr
+	})
+
+	start := time.Now()
+	e := newExecution(context.Background(), nil)
+	err = plan.run(e, clone, nil, 0)
+	e.observe(start, err)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(errs) > 0 {
-		return errs
+	return clone.Interface(), nil
+}
+
+// fieldName returns the name of the struct field the given pointer points to.
+// The role is used to describe the field in the returned errors.
+func fieldName(structValue reflect.Value, field interface{}, role string) (string, error) {
+	fv := reflect.ValueOf(field)
+	if fv.Kind() != reflect.Ptr {
+		return "", fmt.Errorf("%s field expected to be a pointer but got %T", role, field)
+	}
+	ft := findStructField(structValue, fv)
+	if ft == nil {
+		return "", fmt.Errorf("%s field not found", role)
 	}
 
-	return nil
+	return ft.Name, nil
 }
 
-func Transform(from interface{}, to interface{}, transformers ...Transformer) error {
-	v := reflect.ValueOf(to)
-	if v.Kind() != reflect.Ptr {
-		panic(fmt.Errorf("%T must be a pointer", to))
+func (f *FieldTransformer) fieldName(structValue reflect.Value) (string, error) {
+	if c, ok := f.from.(*coalesced); ok {
+		names, err := c.fieldNames(structValue)
+		if err != nil {
+			return "", err
+		}
+
+		return names[0], nil
 	}
 
-	if !v.Elem().CanSet() {
-		panic(fmt.Errorf("%T must be a addressable", to))
+	return fieldName(structValue, f.from, "from")
+}
+
+func (f *FieldTransformer) sources() []interface{} {
+	if c, ok := f.from.(*coalesced); ok {
+		return c.fields
 	}
 
-	tmpTo, err := transform(from, transformers...)
-	if err != nil {
-		return nil
+	return []interface{}{f.from}
+}
+
+func (f *FieldTransformer) targets() []interface{} {
+	return []interface{}{f.to}
+}
+
+func (f *FieldTransformer) dependencies() []interface{} {
+	return nil
+}
+
+func (f *FieldTransformer) pipeline() []Transformer {
+	return f.transformers
+}
+
+func (f *FieldTransformer) rebase(fn func(ptr interface{}) interface{}) Rule {
+	from := f.from
+	if c, ok := f.from.(*coalesced); ok {
+		from = c.rebase(fn)
+	} else {
+		from = fn(from)
 	}
 
-	if tmpTo == nil {
-		return nil
+	return &FieldTransformer{
+		from:         from,
+		to:           fn(f.to),
+		transformers: f.transformers,
 	}
+}
 
-	mustCopyValue(tmpTo, to)
+func (f *FieldTransformer) apply(e *execution, structPtr reflect.Value) error {
+	if c, ok := f.from.(*coalesced); ok {
+		names, err := c.fieldNames(structPtr.Elem())
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return c.transform(e, names, f.to, f.transformers)
+	}
+
+	return e.transformTo(f.from, f.to, f.transformers)
 }
 
-func transform(from interface{}, transformers ...Transformer) (interface{}, error) {
-	tmpTo := from
-	if v, ok := from.(Transformable); ok {
-		var err error
-		if tmpTo, err = v.Transform(); err != nil {
+func (c *coalesced) fieldNames(structValue reflect.Value) ([]string, error) {
+	if len(c.fields) == 0 {
+		return nil, errors.New("coalesce expects at least one field")
+	}
+
+	names := make([]string, len(c.fields))
+	for i, field := range c.fields {
+		name, err := fieldName(structValue, field, "from")
+		if err != nil {
 			return nil, err
 		}
+		names[i] = name
 	}
 
-	tmpTo, err := applyTransformers(tmpTo, transformers...)
-	if err != nil {
-		return nil, err
+	return names, nil
+}
+
+func (c *coalesced) rebase(fn func(ptr interface{}) interface{}) *coalesced {
+	fields := make([]interface{}, len(c.fields))
+	for i, field := range c.fields {
+		fields[i] = fn(field)
 	}
 
-	return tmpTo, nil
+	return &coalesced{fields: fields}
 }
 
-func applyTransformers(from interface{}, transformers ...Transformer) (interface{}, error) {
-	from, _ = indirect(from)
+func (c *coalesced) transform(e *execution, names []string, to interface{}, transformers []Transformer) error {
+	errs := Errors{}
+	for i, field := range c.fields {
+		v, isNil := indirect(field)
+		if isNil || reflect.ValueOf(v).IsZero() {
+			continue
+		}
 
-	if len(transformers) == 0 {
-		return from, nil
+		if err := e.field(names[i]).transformTo(field, to, transformers); err != nil {
+			errs[names[i]] = err
+			continue
+		}
+
+		return nil
 	}
 
-	var to interface{}
-	var err error
-	for _, transformer := range transformers {
-		to, err = transformer.Transform(from)
-		if err != nil {
-			return nil, err
-		}
-		from = to
+	if len(errs) > 0 {
+		return errs
 	}
 
-	return to, nil
+	return nil
+}
+
+// Transform applies the transformers to from and copies the result into to. The error of a
+// failing transformer is returned and to is left untouched.
+func Transform(from interface{}, to interface{}, transformers ...Transformer) error {
+	start := time.Now()
+	e := newExecution(context.Background(), nil)
+	err := e.transformTo(from, to, transformers)
+	e.observe(start, err)
+
+	return err
+}
+
+func transform(from interface{}, transformers ...Transformer) (interface{}, error) {
+	return newExecution(context.Background(), nil).transform(from, transformers)
+}
+
+func applyTransformers(from interface{}, transformers ...Transformer) (interface{}, error) {
+	return newExecution(context.Background(), nil).apply(from, transformers)
 }
 
 func Field(from interface{}, to interface{}, transformers ...Transformer) *FieldTransformer {
@@ -135,13 +323,44 @@ func Each(transformers ...Transformer) *eachTransformer {
 	return &eachTransformer{transformers: transformers}
 }
 
-func Default(value interface{}) *inlineTransformer {
-	return By(func(from interface{}) (interface{}, error) {
-		ifrom, isNil := indirect(from)
-		if isNil || reflect.ValueOf(ifrom).IsZero() {
-			return value, nil
-		}
+// ParallelEach returns a transformer like Each which transforms the elements on the given
+// number of workers. Slices keep the order of their elements. The transformation stops when
+// its context is done. The transformers and interceptors run concurrently, so they have to be
+// safe for concurrent use. A panic while transforming an element fails that element with an
+// error instead of crashing the process.
+func ParallelEach(workers int, transformers ...Transformer) *eachTransformer {
+	if workers < 1 {
+		workers = 1
+	}
 
-		return from, nil
-	})
+	return &eachTransformer{transformers: transformers, workers: workers}
+}
+
+func Default(value interface{}) *defaultTransformer {
+	return &defaultTransformer{value: value}
+}
+
+// Try returns a transformer which runs the given pipeline and, if it fails, falls back
+// to the pipelines added with Or, in order.
+func Try(transformers ...Transformer) *tryTransformer {
+	return &tryTransformer{branches: [][]Transformer{transformers}}
+}
+
+// FirstOf returns a transformer which returns the result of the first branch that does
+// not return an error. If every branch fails, the branch errors are returned as Errors
+// keyed by the branch index.
+func FirstOf(branches ...Transformer) *tryTransformer {
+	t := &tryTransformer{}
+	for _, branch := range branches {
+		t.branches = append(t.branches, []Transformer{branch})
+	}
+
+	return t
+}
+
+// Coalesce can be used as the from argument of Field. The transformers are applied to
+// the first of the given struct fields which is not nil or zero; if they fail, the next
+// non-zero field is tried. If every field fails, all errors are returned.
+func Coalesce(fields ...interface{}) *coalesced {
+	return &coalesced{fields: fields}
 }
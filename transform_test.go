@@ -1,10 +1,14 @@
 package transformation_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/stretchr/testify/assert"
 	"github.com/vcraescu/go-transformation"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -125,6 +129,15 @@ func TestTransform(t *testing.T) {
 	}
 }
 
+func TestTransformError(t *testing.T) {
+	to := "unchanged"
+	err := transformation.Transform(1200, &to, transformation.ToString, transformation.By(func(v interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	}))
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, "unchanged", to)
+}
+
 func TestTransformNil(t *testing.T) {
 	var from *int
 	var to string
@@ -323,3 +336,205 @@ func TestTransformStruct(t *testing.T) {
 func (p Person) Transform() (interface{}, error) {
 	return fmt.Sprintf("%s %s   ", p.FirstName, *p.LastName), nil
 }
+
+func TestTry(t *testing.T) {
+	parse := transformation.Try(transformation.ParseTime(time.RFC3339)).
+		Or(transformation.ParseTime("2006-01-02"))
+
+	var to time.Time
+	err := transformation.Transform("2020-01-02T10:00:00Z", &to, parse)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC), to)
+	}
+
+	err = transformation.Transform("2020-01-02", &to, parse)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), to)
+	}
+
+	err = transformation.Transform("02/01/2020", &to, parse)
+	if assert.Error(t, err) {
+		errs, ok := err.(transformation.Errors)
+		if assert.True(t, ok) {
+			assert.Len(t, errs, 2)
+		}
+	}
+}
+
+func TestFirstOf(t *testing.T) {
+	fail := transformation.By(func(from interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+
+	var to string
+	err := transformation.Transform(" foo ", &to, transformation.FirstOf(fail, transformation.Trim, transformation.Reverse))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "foo", to)
+	}
+
+	err = transformation.Transform(" foo ", &to, transformation.FirstOf(fail, fail))
+	if assert.Error(t, err) {
+		assert.Equal(t, "0: failed; 1: failed.", err.Error())
+	}
+}
+
+func TestTransformStructFieldSlice(t *testing.T) {
+	p := Person{FirstName: "  John  "}
+	fields := []*transformation.FieldTransformer{
+		transformation.Field(&p.FirstName, &p.FirstName, transformation.Trim),
+	}
+	if assert.NoError(t, transformation.TransformStruct(&p, fields...)) {
+		assert.Equal(t, "John", p.FirstName)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	type Profile struct {
+		Nickname  string
+		FirstName string
+		Name      string
+	}
+
+	p := Profile{FirstName: "  John  "}
+	err := transformation.TransformStruct(
+		&p,
+		transformation.Field(transformation.Coalesce(&p.Nickname, &p.FirstName), &p.Name, transformation.Trim),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "John", p.Name)
+	}
+
+	p = Profile{Nickname: "jd", FirstName: "John"}
+	fail := transformation.By(func(from interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("invalid %v", from)
+	})
+	err = transformation.TransformStruct(
+		&p,
+		transformation.Field(transformation.Coalesce(&p.Nickname, &p.FirstName), &p.Name, fail),
+	)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Nickname: (FirstName: invalid John; Nickname: invalid jd.).", err.Error())
+		assert.Zero(t, p.Name)
+	}
+}
+
+func TestTransformedCopy(t *testing.T) {
+	lastName := "  Doe "
+	from := Person{
+		FirstName: " John ",
+		LastName:  &lastName,
+		Addresses: []string{" Street1 "},
+	}
+
+	var first string
+	to, err := transformation.TransformedCopy(
+		&from,
+		transformation.Field(&from.FirstName, &from.FirstName, transformation.Trim),
+		transformation.Field(&from.LastName, &from.LastName, transformation.Trim),
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.Each(transformation.Trim)),
+		transformation.Field(&from.FirstName, &first, transformation.UpperCase),
+	)
+	if assert.NoError(t, err) {
+		p, ok := to.(*Person)
+		if assert.True(t, ok) {
+			assert.Equal(t, "John", p.FirstName)
+			assert.Equal(t, "Doe", *p.LastName)
+			assert.Equal(t, []string{"Street1"}, p.Addresses)
+		}
+		assert.Equal(t, "JOHN", first)
+		assert.Equal(t, " John ", from.FirstName)
+		assert.Equal(t, "  Doe ", lastName)
+		assert.Equal(t, []string{" Street1 "}, from.Addresses)
+	}
+}
+
+func TestParallelEach(t *testing.T) {
+	from := make([]int, 1000)
+	expected := make([]string, len(from))
+	for i := range from {
+		from[i] = i
+		expected[i] = strconv.Itoa(i * 2)
+	}
+
+	var running, maxRunning int32
+	double := transformation.By(func(from interface{}) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(from.(int)%3) * time.Microsecond)
+
+		return from.(int) * 2, nil
+	})
+
+	var to []string
+	err := transformation.Transform(from, &to, transformation.ParallelEach(4, double, transformation.ToString))
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, to)
+		assert.True(t, atomic.LoadInt32(&maxRunning) <= 4)
+	}
+
+	m := map[string]string{"a": " x ", "b": " y"}
+	var mto map[string]string
+	err = transformation.Transform(m, &mto, transformation.ParallelEach(2, transformation.Trim))
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"a": "x", "b": "y"}, mto)
+	}
+}
+
+func TestParallelEachErrors(t *testing.T) {
+	odd := transformation.By(func(from interface{}) (interface{}, error) {
+		if from.(int)%2 == 1 {
+			return nil, fmt.Errorf("%d is odd", from)
+		}
+
+		return from, nil
+	})
+
+	_, err := transformation.ApplyTransformers([]int{0, 1, 2, 3}, transformation.ParallelEach(3, odd))
+	assert.Equal(t, transformation.Errors{
+		"1": errors.New("1 is odd"),
+		"3": errors.New("3 is odd"),
+	}, err)
+
+	_, err = transformation.ApplyTransformers(map[int]int{1: 1, 2: 2}, transformation.ParallelEach(3, odd))
+	assert.Equal(t, transformation.Errors{"1": errors.New("1 is odd")}, err)
+}
+
+func TestParallelEachPanics(t *testing.T) {
+	half := transformation.By(func(from interface{}) (interface{}, error) {
+		if from.(int) == 2 {
+			panic("two")
+		}
+
+		return from.(int) / 2, nil
+	})
+
+	_, err := transformation.ApplyTransformers([]int{4, 2, 6}, transformation.ParallelEach(2, half))
+	assert.Equal(t, transformation.Errors{"1": errors.New("transformation panicked: two")}, err)
+}
+
+func TestParallelEachCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	slow := transformation.By(func(from interface{}) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+
+		return from, nil
+	})
+
+	from := Person{Addresses: make([]string, 100)}
+	err := transformation.TransformStructWithOptions(
+		&from,
+		[]transformation.Option{transformation.WithContext(ctx)},
+		transformation.Field(&from.Addresses, &from.Addresses, transformation.ParallelEach(1, slow)),
+	)
+	assert.Equal(t, transformation.Errors{"Addresses": context.Canceled}, err)
+	assert.True(t, atomic.LoadInt32(&calls) < 100)
+}
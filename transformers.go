@@ -1,11 +1,14 @@
 package transformation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var (
@@ -32,15 +35,37 @@ type (
 
 	ToStringTransformer struct{}
 
+	ParseTimeTransformer struct {
+		layout string
+	}
+
 	inlineTransformer struct {
-		fn TransformFunc
+		fn          TransformFunc
+		name        string
+		description string
 	}
 
 	eachTransformer struct {
 		transformers []Transformer
+		workers      int
+		batchSize    int
+	}
+
+	tryTransformer struct {
+		branches [][]Transformer
+	}
+
+	defaultTransformer struct {
+		value interface{}
 	}
 )
 
+// Money returns a transformer which converts a float amount to an integer amount of its smallest
+// unit, e.g. Money(100) converts 12.34 to 1234.
+func Money(division int) MoneyTransformer {
+	return MoneyTransformer{division: division}
+}
+
 func (t TrimTransformer) Transform(from interface{}) (interface{}, error) {
 	v, err := ToString.Transform(from)
 	if err != nil {
@@ -100,6 +125,15 @@ func (t ToStringTransformer) Transform(from interface{}) (interface{}, error) {
 	return s, nil
 }
 
+// Named returns a copy of the transformer which describes itself with the given name and description.
+func (t *inlineTransformer) Named(name, description string) *inlineTransformer {
+	return &inlineTransformer{
+		fn:          t.fn,
+		name:        name,
+		description: description,
+	}
+}
+
 func (t inlineTransformer) Transform(from interface{}) (interface{}, error) {
 	ifrom, _ := indirect(from)
 
@@ -107,47 +141,172 @@ func (t inlineTransformer) Transform(from interface{}) (interface{}, error) {
 }
 
 func (t eachTransformer) Transform(from interface{}) (interface{}, error) {
-	errs := Errors{}
+	return t.transformIn(newExecution(context.Background(), nil), from)
+}
+
+func (t eachTransformer) transformIn(e *execution, from interface{}) (interface{}, error) {
+	if it, ok := from.(Iterator); ok {
+		return t.transformSequence(e, it)
+	}
 
 	fromValue := reflect.ValueOf(from)
-	var to interface{}
-	var err error
 	switch fromValue.Kind() {
+	case reflect.Chan:
+		if fromValue.Type().ChanDir()&reflect.RecvDir == 0 {
+			return nil, errors.New("must be a channel which can be received from")
+		}
+
+		return t.transformSequence(e, &channelIterator{ctx: e.ctx, ch: fromValue})
 	case reflect.Slice, reflect.Array:
-		sl := make([]interface{}, fromValue.Len())
-		for i := 0; i < fromValue.Len(); i++ {
-			el := fromValue.Index(i).Interface()
-			sl[i], err = transform(el, t.transformers...)
-			if err != nil {
-				errs[strconv.Itoa(i)] = err
-				continue
-			}
+		keys := make([]string, fromValue.Len())
+		values := make([]interface{}, fromValue.Len())
+		for i := range values {
+			keys[i] = strconv.Itoa(i)
+			values[i] = fromValue.Index(i).Interface()
+		}
+
+		sl, err := t.transformElements(e, keys, values)
+		if err != nil {
+			return nil, err
 		}
 
-		to = toConcreteSlice(sl)
+		return toConcreteSlice(sl), nil
 	case reflect.Map:
-		m := make(map[interface{}]interface{})
-		iter := fromValue.MapRange()
-		for iter.Next() {
-			k := iter.Key()
-			v := iter.Value()
-			m[k.Interface()], err = transform(v.Interface(), t.transformers...)
-			if err != nil {
-				errs[k.String()] = err
-				continue
-			}
+		mapKeys := fromValue.MapKeys()
+		keys := make([]string, len(mapKeys))
+		values := make([]interface{}, len(mapKeys))
+		for i, k := range mapKeys {
+			keys[i] = fmt.Sprint(k.Interface())
+			values[i] = fromValue.MapIndex(k).Interface()
+		}
+
+		results, err := t.transformElements(e, keys, values)
+		if err != nil {
+			return nil, err
+		}
+
+		m := make(map[interface{}]interface{}, len(mapKeys))
+		for i, k := range mapKeys {
+			m[k.Interface()] = results[i]
 		}
 
-		to = toConcreteMap(m)
+		return toConcreteMap(m), nil
 	default:
-		return nil, errors.New("must be an iterable (slice, array, map)")
+		return nil, errors.New("must be an iterable (slice, array, map, channel, Iterator)")
+	}
+}
+
+// transformElements runs the pipeline on every value, sequentially or on the workers of the
+// transformer. The failures are returned as Errors keyed by the keys of the failed values.
+func (t eachTransformer) transformElements(e *execution, keys []string, values []interface{}) ([]interface{}, error) {
+	results := make([]interface{}, len(values))
+	failures := make([]error, len(values))
+	copy(results, values)
+
+	// the trace nodes are added upfront so they keep the order of the elements
+	var traced []*execution
+	var nodes []*TraceNode
+	if e.trace != nil {
+		traced = make([]*execution, len(values))
+		nodes = make([]*TraceNode, len(values))
+		for i := range values {
+			traced[i], nodes[i] = e.child(keys[i]).startTrace(traceElement, keys[i], values[i])
+		}
+	}
+
+	for _, seg := range t.segments() {
+		if seg.batch != nil {
+			if err := t.transformBatch(e, seg.first, seg.batch, results, failures); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		run := func(i int) {
+			if failures[i] != nil {
+				return
+			}
+
+			ee := e.child(keys[i])
+			if traced != nil {
+				ee = traced[i]
+			}
+
+			if seg.first == 0 {
+				results[i], failures[i] = ee.transform(results[i], seg.transformers)
+			} else {
+				results[i], failures[i] = ee.applyFrom(results[i], seg.transformers, seg.first)
+			}
+		}
+
+		if t.workers <= 0 {
+			for i := range values {
+				run(i)
+			}
+		} else if err := t.runParallel(e.ctx, len(values), func(i int) {
+			// a panic on a worker would crash the process, so it fails the element instead
+			defer func() {
+				if r := recover(); r != nil {
+					failures[i] = fmt.Errorf("transformation panicked: %v", r)
+				}
+			}()
+			run(i)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range nodes {
+		nodes[i].finish(results[i], failures[i])
+	}
+
+	errs := Errors{}
+	for i, err := range failures {
+		if err != nil {
+			errs[keys[i]] = err
+		}
 	}
 
 	if len(errs) > 0 {
 		return nil, errs
 	}
 
-	return to, nil
+	return results, nil
+}
+
+// runParallel calls run with every index up to n on the workers of the transformer. It stops
+// handing out indexes once the context is done and returns the error of the context.
+func (t eachTransformer) runParallel(ctx context.Context, n int, run func(i int)) error {
+	workers := t.workers
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				run(i)
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return ctx.Err()
 }
 
 func (t eachTransformer) getInterface(value reflect.Value) interface{} {
@@ -177,3 +336,77 @@ func (t ReverseTransformer) Transform(from interface{}) (interface{}, error) {
 
 	return sb.String(), nil
 }
+
+func ParseTime(layout string) ParseTimeTransformer {
+	return ParseTimeTransformer{layout: layout}
+}
+
+func (t ParseTimeTransformer) Transform(from interface{}) (interface{}, error) {
+	ifrom, isNil := indirect(from)
+	if isNil {
+		return nil, nil
+	}
+
+	if tm, ok := ifrom.(time.Time); ok {
+		return tm, nil
+	}
+
+	v, err := ToString.Transform(ifrom)
+	if err != nil {
+		return v, err
+	}
+
+	return time.Parse(t.layout, v.(string))
+}
+
+// Or adds a fallback pipeline which is run when all the previous ones failed.
+func (t *tryTransformer) Or(transformers ...Transformer) *tryTransformer {
+	branches := make([][]Transformer, len(t.branches), len(t.branches)+1)
+	copy(branches, t.branches)
+
+	return &tryTransformer{branches: append(branches, transformers)}
+}
+
+func (t tryTransformer) Transform(from interface{}) (interface{}, error) {
+	return t.transformIn(newExecution(context.Background(), nil), from)
+}
+
+func (t tryTransformer) transformIn(e *execution, from interface{}) (interface{}, error) {
+	errs := Errors{}
+	for i, branch := range t.branches {
+		be, node := e.startTrace(traceBranch, strconv.Itoa(i), from)
+		to, err := be.apply(from, branch)
+		node.finish(to, err)
+		if err != nil {
+			errs[strconv.Itoa(i)] = err
+			continue
+		}
+
+		return to, nil
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return from, nil
+}
+
+func (t defaultTransformer) Transform(from interface{}) (interface{}, error) {
+	return t.transformIn(newExecution(context.Background(), nil), from)
+}
+
+func (t defaultTransformer) transformIn(e *execution, from interface{}) (interface{}, error) {
+	ifrom, isNil := indirect(from)
+	if isNil || reflect.ValueOf(ifrom).IsZero() {
+		_, node := e.startTrace(traceBranch, "default", from)
+		node.finish(t.value, nil)
+
+		return t.value, nil
+	}
+
+	_, node := e.startTrace(traceBranch, "value", from)
+	node.finish(from, nil)
+
+	return from, nil
+}
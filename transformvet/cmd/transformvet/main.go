@@ -0,0 +1,14 @@
+// Command transformvet reports misuses of the transformation package. It can be run on its own
+// or with go vet:
+//
+//	go vet -vettool=$(which transformvet) ./...
+package main
+
+import (
+	"github.com/vcraescu/go-transformation/transformvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(transformvet.Analyzer)
+}
@@ -0,0 +1,85 @@
+package a
+
+import (
+	"time"
+
+	"github.com/vcraescu/go-transformation"
+)
+
+type (
+	Audit struct {
+		CreatedBy string
+	}
+
+	Address struct {
+		Line1 string
+	}
+
+	Person struct {
+		Audit
+		Name     string    `transform:"trim|upper_case"`
+		Email    string    `transform:"trim|down_case|"` // want `malformed transform tag: empty step in pipeline "trim\|down_case\|"`
+		Phone    string    `transform:"slugify"`         // want `malformed transform tag: unknown transformer "slugify"`
+		Code     string    `transform:"trim|reverse"`
+		Salary   float64   `transform:"money(100)"`
+		Bonus    string    `transform:"money(100)"`    // want `transform tag does not accept string: step 1 \(Money\): expected float32 or float64 but got string`
+		Tags     []int     `transform:"each(reverse)"` // want `transform tag does not accept \[\]int: step 1 \(Each\): step 1 \(Reverse\): expected string but got int`
+		Born     time.Time `transform:"parse_time(\"2006-01-02\")"`
+		Address  Address
+		Total    *float64
+		Nickname *string
+	}
+)
+
+func rules() {
+	p := Person{}
+	other := Person{}
+	var name string
+
+	transformation.TransformStructRules(&p,
+		transformation.Field(&p.Name, &p.Name, transformation.Trim),
+		transformation.Field(&p.CreatedBy, &p.CreatedBy, transformation.Trim),
+		transformation.Field(&p.Audit.CreatedBy, &name),
+		transformation.Field(&other.Name, &p.Name),                                                                      // want `&other.Name does not point to a field of p`
+		transformation.Field(&name, &p.Name),                                                                            // want `&name does not point to a field of p`
+		transformation.Field(&p.Address.Line1, &p.Name),                                                                 // want `&p.Address.Line1 points into the field Address which is not embedded in p`
+		transformation.Field(transformation.Coalesce(&p.Name, &other.Email), &p.Name),                                   // want `&other.Email does not point to a field of p`
+		transformation.Computed(&p.Code, func(p *Person) (interface{}, error) { return "", nil }, &p.Name, &other.Name), // want `&other.Name does not point to a field of p`
+		transformation.Split(&other.Name, nil, &p.Name).With(transformation.Trim),                                       // want `&other.Name does not point to a field of p`
+	)
+
+	pp := &p
+	transformation.TransformStructWithOptions(pp, nil,
+		transformation.Field(&pp.Name, &pp.Name),
+		transformation.Field(&p.Name, &pp.Name), // want `&p.Name does not point to a field of pp`
+	)
+}
+
+func pointers() {
+	p := &Person{}
+	var s string
+
+	transformation.Field(&p.Name, p.Name)                   // want `to argument must be a pointer but got string`
+	transformation.Field(p.Name, &p.Name)                   // want `from argument must be a pointer but got string`
+	transformation.Split(&p.Name, nil, &s, s)               // want `to argument must be a pointer but got string`
+	transformation.Transform(" a ", s, transformation.Trim) // want `to argument must be a pointer but got string`
+	transformation.Field(&p.Name, nil)
+}
+
+func pipelines() {
+	p := &Person{}
+	var n int64
+
+	transformation.Field(&p.Name, &n, transformation.Money100)                         // want `pipeline does not accept \*string: step 1 \(Money\): expected float32 or float64 but got string`
+	transformation.Field(p.Total, &n, transformation.Money100, transformation.Reverse) // want `pipeline does not accept \*float64: step 2 \(Reverse\): expected string but got int64`
+	transformation.Field(p.Total, &n, transformation.Money(100), transformation.ToString, transformation.Reverse)
+	transformation.Field(p.Nickname, p.Nickname, transformation.Trim, transformation.Default("n/a"))
+	transformation.Field(p.Nickname, p.Nickname, transformation.Default(42)) // want `pipeline does not accept \*string: step 1 \(Default\): default value of type int is not assignable to string`
+	transformation.Field(&p.Name, &n, transformation.By(nil), transformation.Money100)
+	transformation.Field(&p.Tags, &p.Tags, transformation.Each(transformation.ToString, transformation.Reverse))
+	transformation.Field(&p.Tags, &p.Tags, transformation.Each(transformation.Reverse)) // want `pipeline does not accept \*\[\]int: step 1 \(Each\): step 1 \(Reverse\): expected string but got int`
+	transformation.Field(&p.Tags, &p.Tags, transformation.ParallelEach(4, transformation.ToString, transformation.Reverse))
+	transformation.Field(&p.Tags, &p.Tags, transformation.ParallelEach(4, transformation.Reverse)) // want `pipeline does not accept \*\[\]int: step 1 \(ParallelEach\): step 1 \(Reverse\): expected string but got int`
+	transformation.Transform(1.5, &n, transformation.Money100)
+	transformation.Transform("1.5", &n, transformation.Try(transformation.Money100)) // want `pipeline does not accept string: step 1 \(Try\): 0: step 1 \(Money\): expected float32 or float64 but got string.`
+}
@@ -0,0 +1,7 @@
+module testdata
+
+go 1.22.0
+
+require github.com/vcraescu/go-transformation v0.0.0
+
+replace github.com/vcraescu/go-transformation => ../../
@@ -0,0 +1,492 @@
+// Package transformvet defines an analyzer which reports misuses of the transformation package
+// which would otherwise only fail at runtime:
+//
+//   - rule fields passed to TransformStruct which do not point into the struct being transformed
+//   - to arguments which are not pointers
+//   - malformed transform struct tags
+//   - pipeline steps which do not accept the type of the field, e.g. Money100 on a string field
+//
+// It can be run with go vet:
+//
+//	go vet -vettool=$(which transformvet) ./...
+package transformvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	transformation "github.com/vcraescu/go-transformation"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const pkgPath = "github.com/vcraescu/go-transformation"
+
+// Analyzer reports misuses of the transformation package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "transformvet",
+	Doc:      "check transformation rules, transform tags and pipelines",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// transformers holds the names of the transformers registered at runtime, which are allowed
+// in transform tags.
+var transformers string
+
+func init() {
+	Analyzer.Flags.StringVar(&transformers, "transformers", "", "comma-separated names of the transformers registered at runtime")
+}
+
+// structFuncs maps the functions receiving a struct pointer and rules to the index of their
+// first rule.
+var structFuncs = map[string]int{
+	"TransformStruct":            1,
+	"TransformStructRules":       1,
+	"TransformStructWithOptions": 2,
+	"TransformedCopy":            1,
+	"Describe":                   1,
+	"CheckRules":                 1,
+}
+
+// builtins maps the names of the transformers exported as variables to their values.
+var builtins = map[string]transformation.Transformer{
+	"Trim":      transformation.Trim,
+	"Money100":  transformation.Money100,
+	"ToString":  transformation.ToString,
+	"Reverse":   transformation.Reverse,
+	"UpperCase": transformation.UpperCase,
+	"DownCase":  transformation.DownCase,
+}
+
+// opaque stands for a transformer which is not known statically.
+type opaque struct{}
+
+func (opaque) Transform(from interface{}) (interface{}, error) {
+	return from, nil
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	registered := map[string]bool{}
+	for _, name := range transformation.RegisteredTransformers() {
+		registered[name] = true
+	}
+
+	for _, name := range strings.Split(transformers, ",") {
+		if name = strings.TrimSpace(name); name != "" && !registered[name] {
+			transformation.RegisterTransformer(name, func(args ...string) (transformation.Transformer, error) {
+				return opaque{}, nil
+			})
+		}
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodes := []ast.Node{(*ast.StructType)(nil), (*ast.CallExpr)(nil)}
+	insp.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.StructType:
+			checkTags(pass, n)
+		case *ast.CallExpr:
+			checkCall(pass, n)
+		}
+	})
+
+	return nil, nil
+}
+
+func checkTags(pass *analysis.Pass, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+
+		pipeline, ok := reflect.StructTag(tag).Lookup(transformation.TagName)
+		if !ok || pipeline == "-" {
+			continue
+		}
+
+		ts, err := transformation.ParsePipeline(pipeline)
+		if err != nil {
+			pass.Reportf(field.Tag.Pos(), "malformed transform tag: %v", err)
+			continue
+		}
+
+		if rt := reflectType(pass.TypesInfo.TypeOf(field.Type)); rt != nil {
+			if _, err := transformation.Check(rt, ts...); err != nil {
+				pass.Reportf(field.Tag.Pos(), "transform tag does not accept %s: %v", rt, err)
+			}
+		}
+	}
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := callee(pass, call)
+	if fn == nil || fn.Type().(*types.Signature).Recv() != nil {
+		return
+	}
+
+	switch fn.Name() {
+	case "Field":
+		if len(call.Args) < 2 {
+			return
+		}
+		checkPointer(pass, call.Args[0], "from")
+		checkPointer(pass, call.Args[1], "to")
+		checkPipeline(pass, call, call.Args[0], 2)
+	case "Split":
+		for _, arg := range call.Args[2:] {
+			checkPointer(pass, arg, "to")
+		}
+	case "Transform":
+		if len(call.Args) < 2 {
+			return
+		}
+		checkPointer(pass, call.Args[1], "to")
+		checkPipeline(pass, call, call.Args[0], 2)
+	default:
+		if first, ok := structFuncs[fn.Name()]; ok && len(call.Args) > first {
+			checkRules(pass, call.Args[0], call.Args[first:])
+		}
+	}
+}
+
+// checkRules reports the rule fields which do not point into the struct the from expression
+// points to.
+func checkRules(pass *analysis.Pass, from ast.Expr, rules []ast.Expr) {
+	root := rootObject(pass, from)
+	if root == nil {
+		return
+	}
+
+	for _, rule := range rules {
+		for _, field := range ruleFields(pass, rule) {
+			checkField(pass, root, field)
+		}
+	}
+}
+
+// ruleFields returns the arguments of the rule which have to point to a field of the struct.
+func ruleFields(pass *analysis.Pass, rule ast.Expr) []ast.Expr {
+	call, ok := ast.Unparen(rule).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+
+	fn := callee(pass, call)
+	if fn == nil || len(call.Args) == 0 {
+		return nil
+	}
+
+	switch fn.Name() {
+	case "Field":
+		if c, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr); ok {
+			if f := callee(pass, c); f != nil && f.Name() == "Coalesce" {
+				return c.Args
+			}
+		}
+
+		return call.Args[:1]
+	case "Computed":
+		fields := []ast.Expr{call.Args[0]}
+		if len(call.Args) > 2 {
+			fields = append(fields, call.Args[2:]...)
+		}
+
+		return fields
+	case "Split":
+		return call.Args[:1]
+	case "With":
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			return ruleFields(pass, sel.X)
+		}
+	}
+
+	return nil
+}
+
+// checkField reports the address of a variable or of a field which is not reachable from the
+// root through embedded fields only.
+func checkField(pass *analysis.Pass, root types.Object, field ast.Expr) {
+	addr, ok := ast.Unparen(field).(*ast.UnaryExpr)
+	if !ok || addr.Op != token.AND {
+		return
+	}
+
+	x := ast.Unparen(addr.X)
+	if ident, ok := x.(*ast.Ident); ok {
+		if obj := pass.TypesInfo.Uses[ident]; obj != nil && obj != root {
+			pass.Reportf(field.Pos(), "%s does not point to a field of %s", types.ExprString(field), root.Name())
+		}
+		return
+	}
+
+	sel, ok := x.(*ast.SelectorExpr)
+	if !ok || !isField(pass, sel) {
+		return
+	}
+
+	for {
+		switch inner := ast.Unparen(sel.X).(type) {
+		case *ast.Ident:
+			if obj := pass.TypesInfo.Uses[inner]; obj != nil && obj != root {
+				pass.Reportf(field.Pos(), "%s does not point to a field of %s", types.ExprString(field), root.Name())
+			}
+			return
+		case *ast.SelectorExpr:
+			if !isField(pass, inner) {
+				return
+			}
+
+			if v, _ := pass.TypesInfo.Selections[inner].Obj().(*types.Var); v == nil || !v.Anonymous() {
+				pass.Reportf(field.Pos(), "%s points into the field %s which is not embedded in %s", types.ExprString(field), inner.Sel.Name, root.Name())
+				return
+			}
+			sel = inner
+		default:
+			return
+		}
+	}
+}
+
+func isField(pass *analysis.Pass, sel *ast.SelectorExpr) bool {
+	s := pass.TypesInfo.Selections[sel]
+
+	return s != nil && s.Kind() == types.FieldVal
+}
+
+// rootObject returns the variable holding the struct, or the pointer to the struct, the from
+// expression points to.
+func rootObject(pass *analysis.Pass, from ast.Expr) types.Object {
+	x := ast.Unparen(from)
+	if addr, ok := x.(*ast.UnaryExpr); ok && addr.Op == token.AND {
+		x = ast.Unparen(addr.X)
+	} else if _, ok := pass.TypesInfo.TypeOf(x).Underlying().(*types.Pointer); !ok {
+		return nil
+	}
+
+	ident, ok := x.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	return pass.TypesInfo.Uses[ident]
+}
+
+// checkPointer reports the argument if its static type is not a pointer.
+func checkPointer(pass *analysis.Pass, arg ast.Expr, role string) {
+	t := pass.TypesInfo.TypeOf(arg)
+	if t == nil {
+		return
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return
+	case *types.Basic:
+		if u.Kind() == types.UntypedNil {
+			return
+		}
+	}
+
+	pass.Reportf(arg.Pos(), "%s argument must be a pointer but got %s", role, t)
+}
+
+// checkPipeline reports the steps of the pipeline starting at the given argument which do not
+// accept the type produced by the steps before them.
+func checkPipeline(pass *analysis.Pass, call *ast.CallExpr, from ast.Expr, first int) {
+	if call.Ellipsis.IsValid() || len(call.Args) <= first {
+		return
+	}
+
+	rt := reflectType(pass.TypesInfo.TypeOf(from))
+	if rt == nil {
+		return
+	}
+
+	if _, err := transformation.Check(rt, evalTransformers(pass, call.Args[first:])...); err != nil {
+		pass.Reportf(call.Args[first].Pos(), "pipeline does not accept %s: %v", rt, err)
+	}
+}
+
+func evalTransformers(pass *analysis.Pass, exprs []ast.Expr) []transformation.Transformer {
+	ts := make([]transformation.Transformer, len(exprs))
+	for i, expr := range exprs {
+		ts[i] = evalTransformer(pass, expr)
+	}
+
+	return ts
+}
+
+// evalTransformer returns the transformer the expression evaluates to, if it is one of the
+// built-in transformers, or an opaque transformer otherwise.
+func evalTransformer(pass *analysis.Pass, expr ast.Expr) transformation.Transformer {
+	expr = ast.Unparen(expr)
+	if call, ok := expr.(*ast.CallExpr); ok {
+		return evalCall(pass, call)
+	}
+
+	var ident *ast.Ident
+	switch x := expr.(type) {
+	case *ast.Ident:
+		ident = x
+	case *ast.SelectorExpr:
+		ident = x.Sel
+	default:
+		return opaque{}
+	}
+
+	if v, ok := pass.TypesInfo.Uses[ident].(*types.Var); ok && v.Pkg() != nil && v.Pkg().Path() == pkgPath && v.Parent() == v.Pkg().Scope() {
+		if t, ok := builtins[v.Name()]; ok {
+			return t
+		}
+	}
+
+	return opaque{}
+}
+
+func evalCall(pass *analysis.Pass, call *ast.CallExpr) transformation.Transformer {
+	fn := callee(pass, call)
+	if fn == nil || call.Ellipsis.IsValid() {
+		return opaque{}
+	}
+
+	if sig, _ := fn.Type().(*types.Signature); sig == nil || sig.Recv() != nil {
+		return opaque{}
+	}
+
+	switch fn.Name() {
+	case "Money":
+		if v, ok := constValue(pass, call.Args[0]).(int64); ok && v > 0 {
+			return transformation.Money(int(v))
+		}
+
+		return transformation.Money(1)
+	case "ParseTime":
+		return transformation.ParseTime(time.RFC3339)
+	case "Default":
+		if v := constValue(pass, call.Args[0]); v != nil {
+			return transformation.Default(v)
+		}
+	case "Each":
+		return transformation.Each(evalTransformers(pass, call.Args)...)
+	case "ParallelEach":
+		return transformation.ParallelEach(1, evalTransformers(pass, call.Args[1:])...)
+	case "Try":
+		return transformation.Try(evalTransformers(pass, call.Args)...)
+	}
+
+	return opaque{}
+}
+
+// constValue returns the value of a constant expression converted to its type, or to its
+// default type if it is untyped.
+func constValue(pass *analysis.Pass, expr ast.Expr) interface{} {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil {
+		return nil
+	}
+
+	rt := reflectType(types.Default(tv.Type))
+	if rt == nil {
+		return nil
+	}
+
+	var v interface{}
+	switch tv.Value.Kind() {
+	case constant.String:
+		v = constant.StringVal(tv.Value)
+	case constant.Bool:
+		v = constant.BoolVal(tv.Value)
+	case constant.Int:
+		i, exact := constant.Int64Val(tv.Value)
+		if !exact {
+			return nil
+		}
+		v = i
+	case constant.Float:
+		f, _ := constant.Float64Val(tv.Value)
+		v = f
+	default:
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.Type().ConvertibleTo(rt) {
+		return nil
+	}
+
+	return rv.Convert(rt).Interface()
+}
+
+// reflectType returns the runtime type of the given type, or nil if it cannot be built, e.g.
+// for named types other than time.Time.
+func reflectType(t types.Type) reflect.Type {
+	switch t := t.(type) {
+	case *types.Basic:
+		return basicTypes[t.Kind()]
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return reflect.TypeOf(time.Time{})
+		}
+	case *types.Pointer:
+		if elem := reflectType(t.Elem()); elem != nil {
+			return reflect.PtrTo(elem)
+		}
+	case *types.Slice:
+		if elem := reflectType(t.Elem()); elem != nil {
+			return reflect.SliceOf(elem)
+		}
+	case *types.Array:
+		if elem := reflectType(t.Elem()); elem != nil {
+			return reflect.ArrayOf(int(t.Len()), elem)
+		}
+	case *types.Map:
+		key := reflectType(t.Key())
+		elem := reflectType(t.Elem())
+		if key != nil && elem != nil {
+			return reflect.MapOf(key, elem)
+		}
+	}
+
+	return nil
+}
+
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Bool:    reflect.TypeOf(false),
+	types.Int:     reflect.TypeOf(int(0)),
+	types.Int8:    reflect.TypeOf(int8(0)),
+	types.Int16:   reflect.TypeOf(int16(0)),
+	types.Int32:   reflect.TypeOf(int32(0)),
+	types.Int64:   reflect.TypeOf(int64(0)),
+	types.Uint:    reflect.TypeOf(uint(0)),
+	types.Uint8:   reflect.TypeOf(uint8(0)),
+	types.Uint16:  reflect.TypeOf(uint16(0)),
+	types.Uint32:  reflect.TypeOf(uint32(0)),
+	types.Uint64:  reflect.TypeOf(uint64(0)),
+	types.Float32: reflect.TypeOf(float32(0)),
+	types.Float64: reflect.TypeOf(float64(0)),
+	types.String:  reflect.TypeOf(""),
+}
+
+// callee returns the function of the transformation package called by the call expression.
+func callee(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fn, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return nil
+	}
+
+	return fn
+}
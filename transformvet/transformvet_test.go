@@ -0,0 +1,12 @@
+package transformvet_test
+
+import (
+	"testing"
+
+	"github.com/vcraescu/go-transformation/transformvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), transformvet.Analyzer, "./a")
+}
@@ -3,6 +3,7 @@ package transformation
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // findStructField looks for a field in the given struct.
@@ -51,7 +52,7 @@ func copyValue(src interface{}, dest interface{}) error {
 	case reflect.Slice, reflect.Array:
 		return copySlice(src, dest)
 	case reflect.Map:
-		panic("not implemented")
+		return copyMap(src, dest)
 	default:
 		if destValue.Kind() == reflect.Ptr && destValue.Elem().Kind() == reflect.Ptr && srcValue.Kind() != reflect.Ptr {
 			srcValue = makePtr(srcValue)
@@ -67,6 +68,63 @@ func copyValue(src interface{}, dest interface{}) error {
 	}
 }
 
+// convertNumber converts a number to the given numeric type, or to the type the given pointer
+// type points to. Any other value is returned as it is.
+func convertNumber(v reflect.Value, t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if v.Type() == t || !isNumberKind(v.Kind()) || !isNumberKind(t.Kind()) {
+		return v
+	}
+
+	return v.Convert(t)
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// isHashable reports whether the value can be used as a map key. Unlike the Comparable method
+// of its type, it checks the dynamic values of interfaces too, so a struct with an interface{}
+// field holding a slice is not hashable.
+func isHashable(v interface{}) bool {
+	return v == nil || isHashableValue(reflect.ValueOf(v))
+}
+
+func isHashableValue(v reflect.Value) bool {
+	if !v.Type().Comparable() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || isHashableValue(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isHashableValue(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isHashableValue(v.Index(i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 func mustCopyValue(src interface{}, dest interface{}) {
 	if err := copyValue(src, dest); err != nil {
 		panic(err)
@@ -214,6 +272,24 @@ func sliceHasSameType(i []interface{}) bool {
 	return true
 }
 
+// commonType returns the type of the given values if they all have the same one, or the empty
+// interface type otherwise.
+func commonType(values []interface{}) reflect.Type {
+	interfaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+	if len(values) == 0 || values[0] == nil {
+		return interfaceType
+	}
+
+	t := reflect.TypeOf(values[0])
+	for _, v := range values[1:] {
+		if reflect.TypeOf(v) != t {
+			return interfaceType
+		}
+	}
+
+	return t
+}
+
 func copySlice(src interface{}, dest interface{}) error {
 	srcValue := reflect.ValueOf(src)
 	destValue := reflect.ValueOf(dest)
@@ -231,6 +307,41 @@ func copySlice(src interface{}, dest interface{}) error {
 	return nil
 }
 
+func copyMap(src interface{}, dest interface{}) error {
+	srcValue := reflect.ValueOf(src)
+	destValue := reflect.ValueOf(dest)
+	mt := destValue.Type().Elem()
+	if mt.Kind() == reflect.Ptr {
+		mt = mt.Elem()
+	}
+
+	if mt.Kind() != reflect.Map {
+		assign(destValue, srcValue)
+
+		return nil
+	}
+
+	m := reflect.MakeMapWithSize(mt, srcValue.Len())
+	iter := srcValue.MapRange()
+	for iter.Next() {
+		k := reflect.New(mt.Key())
+		if err := copyValue(iter.Key().Interface(), k.Interface()); err != nil {
+			return err
+		}
+
+		v := reflect.New(mt.Elem())
+		if err := copyValue(iter.Value().Interface(), v.Interface()); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(k.Elem(), v.Elem())
+	}
+
+	assign(destValue, m)
+
+	return nil
+}
+
 func assign(to, from reflect.Value) {
 	if to.Kind() != reflect.Ptr {
 		panic(fmt.Errorf("expected %s but got %s", reflect.Ptr, to.Kind()))
@@ -259,3 +370,116 @@ func makeSliceFrom(from reflect.Value, len, cap int) reflect.Value {
 
 	return reflect.MakeSlice(st, len, cap)
 }
+
+// deepCopy returns a copy of the given value which shares no pointers, slices or maps with it.
+// Unexported struct fields, funcs and channels are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	return deepCopyValue(v, map[copiedPtr]reflect.Value{})
+}
+
+type copiedPtr struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+func deepCopyValue(v reflect.Value, seen map[copiedPtr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := copiedPtr{typ: v.Type(), ptr: v.Pointer()}
+		if c, ok := seen[key]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[key] = c
+		c.Elem().Set(deepCopyValue(v.Elem(), seen))
+
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyValue(v.Elem(), seen))
+
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(deepCopyValue(v.Field(i), seen))
+			}
+		}
+
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), seen))
+		}
+
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), seen))
+		}
+
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopyValue(iter.Value(), seen))
+		}
+
+		return c
+	default:
+		return v
+	}
+}
+
+// transformerName returns the name of the given transformer. Unless the transformer is a
+// Describer, the name is derived from its type, e.g. Trim for TrimTransformer.
+func transformerName(t Transformer) string {
+	if d, ok := t.(Describer); ok {
+		return d.Name()
+	}
+
+	typ := reflect.TypeOf(t)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return ""
+	}
+
+	name := strings.TrimSuffix(typ.Name(), "Transformer")
+	if name == "" {
+		return typ.String()
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func transformerNames(transformers []Transformer) []string {
+	if len(transformers) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(transformers))
+	for i, t := range transformers {
+		names[i] = transformerName(t)
+	}
+
+	return names
+}
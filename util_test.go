@@ -293,3 +293,56 @@ func TestIndirect(t *testing.T) {
 		assert.Equal(t, test.isNil, isNil, test.tag)
 	}
 }
+
+func TestDeepCopy(t *testing.T) {
+	type Node struct {
+		Name     string
+		Tags     []string
+		Attrs    map[string]*int
+		Parent   *Node
+		Children []*Node
+		Any      interface{}
+	}
+
+	n := 1
+	parent := &Node{Name: "parent"}
+	from := &Node{
+		Name:   "child",
+		Tags:   []string{"a", "b"},
+		Attrs:  map[string]*int{"n": &n},
+		Parent: parent,
+		Any:    []int{1},
+	}
+	parent.Children = []*Node{from}
+
+	to := transformation.DeepCopy(reflect.ValueOf(from)).Interface().(*Node)
+	if assert.Equal(t, from, to) {
+		assert.NotSame(t, from, to)
+		assert.NotSame(t, from.Parent, to.Parent)
+		assert.NotSame(t, from.Attrs["n"], to.Attrs["n"])
+		assert.Same(t, to, to.Parent.Children[0])
+
+		to.Tags[0] = "c"
+		to.Any.([]int)[0] = 2
+		assert.Equal(t, "a", from.Tags[0])
+		assert.Equal(t, 1, from.Any.([]int)[0])
+	}
+}
+
+func TestCopyValueMap(t *testing.T) {
+	from := map[int]string{1: "a", 2: "b"}
+	var to map[int]*string
+
+	err := transformation.CopyValue(from, &to)
+	if assert.NoError(t, err) && assert.Len(t, to, len(from)) {
+		for k, v := range to {
+			assert.Equal(t, from[k], *v)
+		}
+	}
+
+	var toPtr *map[int]string
+	err = transformation.CopyValue(&from, &toPtr)
+	if assert.NoError(t, err) && assert.NotNil(t, toPtr) {
+		assert.Equal(t, from, *toPtr)
+	}
+}